@@ -9,8 +9,9 @@
 // will either be the name of the output directory, if the output is a
 // file, or the working directory.
 //
-// If the JSON is an array of elements, e.g. []T or []map[string]T, the
-// first element will be used to generate the definitions.
+// If the JSON is an array of elements, e.g. []T or []map[string]T, each
+// element is treated as a sample of T and the definitions are generated from
+// all of them.
 //
 // By default a struct type will be generated, unless the -maptype flag is
 // used.
@@ -34,6 +35,9 @@ package main
 import (
 	"flag"
 	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"strings"
@@ -67,6 +71,45 @@ func (s stringArr) Get() []string {
 	return s
 }
 
+// outFile is an output file.  It's written to a temporary file, in the same
+// directory, that replaces the file at path when it's committed, so that
+// nothing is written if generation fails.
+type outFile struct {
+	*os.File
+	path string
+}
+
+// createOut creates the temporary file for the output file at path.
+func createOut(path string) (*outFile, error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".")
+	if err != nil {
+		return nil, err
+	}
+	err = f.Chmod(0644)
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+	return &outFile{File: f, path: path}, nil
+}
+
+// commit closes the temporary file and renames it to the output file.
+func (f *outFile) commit() error {
+	err := f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), f.path)
+}
+
+// discard closes and removes the temporary file.
+func (f *outFile) discard() {
+	f.Close()
+	os.Remove(f.Name())
+}
+
 var (
 	name       string
 	pkg        string
@@ -76,14 +119,38 @@ var (
 	writeJSON  bool
 	importJSON bool
 	mapType    bool
+	strEncoded bool
+	enums      int
+	strictEnum bool
 	help       bool
+	namer      string
+	fieldOrder string
+	typeOrder  string
+	tmplFile   string
+	noFormat   bool
+	comments   bool
+	genTest    bool
+	verify     bool
+	from       string
+	inFormat   string
+	nodePath   string
+	format     string
+	operations bool
+	split      bool
+	pages      bool
+	pageKeys   string
+	components stringArr
 	tagKeys    stringArr
+	irregulars stringArr
+	tagTmpls   stringArr
+	addInits   stringArr
+	rmInits    stringArr
 )
 
 func init() {
 	flag.StringVar(&name, "name", "", "the name of the type")
 	flag.StringVar(&name, "n", "", "the short flag for -name")
-	flag.StringVar(&input, "input", "stdin", "the path to the input file; if not specified stdin is used")
+	flag.StringVar(&input, "input", "stdin", "the path to the input file; if not specified stdin is used; gzip, zlib, and bzip2 compressed input is decompressed")
 	flag.StringVar(&input, "i", "stdin", "the short flag for -input")
 	flag.StringVar(&output, "output", "stdout", "path to the output file; if not specified stdout is used")
 	flag.StringVar(&output, "o", "stdout", "the short flag for -output")
@@ -97,10 +164,34 @@ func init() {
 	flag.BoolVar(&importJSON, "a", false, "the short flag for -addimport")
 	flag.BoolVar(&mapType, "maptype", false, "the provided json is a map type; not a struct type")
 	flag.BoolVar(&mapType, "m", false, "the short flag for -maptype")
+	flag.BoolVar(&strEncoded, "stringencoded", false, "type fields whose string values all encode numbers or bools as those types")
+	flag.IntVar(&enums, "enums", 0, "the maximum number of distinct values a string field may have to be an enum; 0 disables enum detection")
+	flag.BoolVar(&strictEnum, "strictenums", false, "generate UnmarshalJSON methods that reject unknown enum values")
 	flag.BoolVar(&help, "help", false, "json2go help")
 	flag.BoolVar(&help, "h", false, "the short flag for -help")
-	flag.Var(&tagKeys, "tagkeys", "additional struct tag keys; can be used more than once")
+	flag.Var(&tagKeys, "tagkeys", "additional struct tag keys, as key[:format][,opts]; can be used more than once")
 	flag.Var(&tagKeys, "t", "the short flag for -tagkeys")
+	flag.StringVar(&tmplFile, "template", "", "path to a text/template file used to generate the output")
+	flag.BoolVar(&noFormat, "noformat", false, "don't gofmt the output; for templates that don't generate Go")
+	flag.StringVar(&namer, "namer", "word", "how keys are split into words for naming: word or underscore")
+	flag.StringVar(&format, "format", "go", "the format of the output: go or jsonschema")
+	flag.StringVar(&inFormat, "input-format", "", "the encoding of the input: json, yaml, toml, or json5, lenient JSON with comments and trailing commas; if not specified, it is detected from the input file's extension, otherwise json")
+	flag.StringVar(&nodePath, "path", "", "a JSON Pointer, e.g. /data/items, to the node the types are generated from; * matches every element or value")
+	flag.StringVar(&from, "from", "samples", "what the input is: samples of the type, a JSON Schema, an OpenAPI 3 document, or a HAR file: samples, schema, openapi, or har")
+	flag.Var(&components, "component", "the name of an OpenAPI component schema to generate a type for; can be used more than once; if not set, all of them are")
+	flag.BoolVar(&split, "split", false, "with -from har, write the types of each endpoint to its own file in the -output directory")
+	flag.BoolVar(&pages, "pages", false, "generate a generic Page[T] for pagination envelopes that are shared by the types, with each type being a Page of its items")
+	flag.StringVar(&pageKeys, "pagekeys", "", "the keys of the pagination envelope, e.g. data,next_cursor,total; the first is the items; implies -pages")
+	flag.BoolVar(&operations, "operations", false, "generate types for the request and response bodies of each OpenAPI operation")
+	flag.BoolVar(&verify, "verify", false, "type-check the generated code and check that every sample decodes into it before writing it")
+	flag.BoolVar(&genTest, "gentest", false, "generate a _test.go file that checks the source JSON round-trips through the type; the output must be a file")
+	flag.BoolVar(&comments, "comments", false, "add a comment to each field with an example value, its JSON path, presence, and observed ranges")
+	flag.StringVar(&fieldOrder, "order", "alpha", "the order of struct fields: alpha, source, or required")
+	flag.StringVar(&typeOrder, "typeorder", "breadth", "the order of nested type declarations: breadth, depth, or alpha")
+	flag.Var(&tagTmpls, "tagtemplate", "an additional struct tag whose value is a template, as key=template; can be used more than once")
+	flag.Var(&addInits, "initialism", "an initialism, e.g. SKU, to uppercase in names; can be used more than once")
+	flag.Var(&rmInits, "noinitialism", "a common initialism, e.g. ID, to not uppercase in names; can be used more than once")
+	flag.Var(&irregulars, "irregular", "an irregular plural, as singular:plural, used when naming array element types; can be used more than once")
 }
 
 func main() {
@@ -126,7 +217,34 @@ func realMain() int {
 		fmt.Fprintln(os.Stderr, "\nstruct2json error: name of struct must be provided using the -n or -name flag.\nUse the '-h', '-help', or 'help' flag for more information about json2go flags.")
 		return 1
 	}
-	var in, out, jsn *os.File
+	// the type's name is the title cased name, e.g. func is Func, so it
+	// is what must be an identifier
+	if !token.IsIdentifier(strings.Title(name)) {
+		fmt.Fprintf(os.Stderr, "\njson2go error: invalid -name %q: the name of the type must be a valid Go identifier.\n", name)
+		return 1
+	}
+	if genTest && output == "stdout" {
+		fmt.Fprintln(os.Stderr, "\njson2go error: -gentest requires -output to be a file.")
+		return 1
+	}
+	if split && (from != "har" || output == "stdout") {
+		fmt.Fprintln(os.Stderr, "\njson2go error: -split requires -from har and -output to be a directory.")
+		return 1
+	}
+	if split && writeJSON {
+		fmt.Fprintln(os.Stderr, "\njson2go error: -writejson can't be used with -split.")
+		return 1
+	}
+	var in *os.File
+	var out io.Writer
+	var jsn, tst *outFile
+	// files are the output files; they are only committed if Gen succeeds
+	var files []*outFile
+	defer func() {
+		for _, f := range files {
+			f.discard()
+		}
+	}()
 	var err error
 	// set input
 	in = os.Stdin
@@ -140,14 +258,30 @@ func realMain() int {
 	defer in.Close()
 	// set output
 	out = os.Stdout
-	if output != "stdout" {
-		//
-		out, err = os.OpenFile(output, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if split {
+		// the output is the directory the endpoints' files are
+		// written to
+		err = os.MkdirAll(output, 0755)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
-		defer out.Close()
+		if len(pkg) == 0 {
+			dir, err := filepath.Abs(output)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			pkg = filepath.Base(dir)
+		}
+	} else if output != "stdout" {
+		f, err := createOut(output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		files = append(files, f)
+		out = f
 		// set the package name, if one isn't set
 		if len(pkg) == 0 {
 			// get the rooted path to the output
@@ -163,12 +297,21 @@ func realMain() int {
 		}
 		// write the source json if applicable
 		if writeJSON {
-			jsn, err = os.OpenFile(fmt.Sprintf("%s.json", strings.TrimSuffix(output, filepath.Ext(output))), os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+			jsn, err = createOut(fmt.Sprintf("%s.json", strings.TrimSuffix(output, filepath.Ext(output))))
 			if err != nil {
 				fmt.Fprintln(os.Stderr, err)
 				return 1
 			}
-			defer jsn.Close()
+			files = append(files, jsn)
+		}
+		// create the test file if applicable
+		if genTest {
+			tst, err = createOut(fmt.Sprintf("%s_test.go", strings.TrimSuffix(output, filepath.Ext(output))))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			files = append(files, tst)
 		}
 	}
 	// there is a chance pkg hasn't been set; get the wd and set pkg to
@@ -191,19 +334,149 @@ func realMain() int {
 		t.WriteJSON = writeJSON
 		t.SetJSONWriter(jsn)
 	}
+	if genTest {
+		t.GenTest = genTest
+		t.SetTestWriter(tst)
+		if writeJSON {
+			t.SetTestJSONFile(filepath.Base(jsn.path))
+		}
+	}
 	t.ImportJSON = importJSON
 	if pkg != "" {
-		t.SetPkg(pkg)
+		err = t.SetPkg(pkg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s; use -pkg to set a valid package name\n", err)
+			return 1
+		}
 	}
 	t.MapType = mapType
+	t.StringEncoded = strEncoded
+	t.EnumThreshold = enums
+	t.StrictEnums = strictEnum
 	t.SetStructName(structName)
-	t.SetTagKeys(tagKeys.Get())
+	err = t.SetTagKeys(tagKeys.Get())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if tmplFile != "" {
+		b, err := ioutil.ReadFile(tmplFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		err = t.SetTemplate(string(b))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	t.SkipFormat = noFormat
+	t.Comments = comments
+	if inFormat != "" {
+		t.InputFormat, err = json2go.ParseInputFormat(inFormat)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	} else if input != "stdin" {
+		t.InputFormat, _ = json2go.InputFormatOf(input)
+	}
+	if input != "stdin" {
+		// compressed input is otherwise detected by its magic bytes
+		if c, ok := json2go.CompressionOf(input); ok {
+			t.Compression = c
+		}
+	}
+	t.From, err = json2go.ParseInput(from)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	err = t.SetPath(nodePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	t.SetComponents(components.Get()...)
+	t.Pages = pages
+	if pageKeys != "" {
+		keys := strings.Split(pageKeys, ",")
+		t.SetPageKeys(keys[0], keys[1:]...)
+	}
+	if split {
+		t.SetEndpointWriter(func(name string) (io.Writer, error) {
+			f, err := createOut(filepath.Join(output, json2go.SnakeCase(name)+".go"))
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, f)
+			return f, nil
+		})
+	}
+	t.Operations = operations
+	t.Format, err = json2go.ParseOutputFormat(format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	t.Verify = verify
+	t.FieldOrder, err = json2go.ParseFieldOrder(fieldOrder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	t.TypeOrder, err = json2go.ParseTypeOrder(typeOrder)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	for _, v := range tagTmpls.Get() {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			fmt.Fprintf(os.Stderr, "invalid -tagtemplate value %q: expected key=template\n", v)
+			return 1
+		}
+		k, err := json2go.ParseTagTemplate(parts[0], parts[1])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid -tagtemplate value %q: %s\n", v, err)
+			return 1
+		}
+		t.AddTagKey(k)
+	}
+	t.AddInitialisms(addInits.Get()...)
+	t.RemoveInitialisms(rmInits.Get()...)
+	switch namer {
+	case "word":
+		t.SetNamer(json2go.WordNamer{})
+	case "underscore":
+		t.SetNamer(json2go.UnderscoreNamer{})
+	default:
+		fmt.Fprintf(os.Stderr, "invalid -namer value %q: expected word or underscore\n", namer)
+		return 1
+	}
+	for _, v := range irregulars.Get() {
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			fmt.Fprintf(os.Stderr, "invalid -irregular value %q: expected singular:plural\n", v)
+			return 1
+		}
+		t.AddIrregular(parts[0], parts[1])
+	}
 	// Generate the Go Types
 	err = t.Gen()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		return 1
 	}
+	for len(files) > 0 {
+		err = files[0].commit()
+		files = files[1:]
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
 	return 0
 }
 
@@ -241,7 +514,10 @@ Options:
 flag              default   description
 ---------------   -------   ------------------------------------------
 -n  -name                   The name of the type: required.
--i  -input        stdin     The JSON input source.
+-i  -input        stdin     The JSON input source.  Input that is
+                            gzip, zlib, or bzip2 compressed, detected
+                            by its magic bytes or its extension, .gz,
+                            .zz, .zlib, or .bz2, is decompressed.
 -o  -output       stdout    The Go srouce code output destination.
 -w  -writejson    false     Write the source JSON to file; only valid
                             when the output is a file.
@@ -251,9 +527,133 @@ flag              default   description
                             of a struct type.
 -s  -structname   Struct    The name of the struct; only used in
                             conjunction with -maptype.
+    -stringencoded false    Type fields whose string values all
+                            encode numbers or bools as int64,
+                            float64, or bool with the ',string' tag
+                            option.
+    -enums        0         The maximum number of distinct values a
+                            string field may have, across multiple
+                            samples, to be defined as an enum; 0
+                            disables enum detection.
+    -strictenums  false     Generate an UnmarshalJSON method for each
+                            enum that rejects unknown values.
 -h  -help         false     Print the help text; 'help' is also valid.
 -t  -tagkey                 Additional key to be added to struct tags.
                             For multiple keys, use one per key value.
+                            A key may specify the format of its value
+                            and options as key[:format][,opts], e.g.
+                            db:snake or yaml:kebab,omitempty.  The
+                            formats are: snake, kebab, camel, pascal,
+                            lower, and upper.
+    -tagtemplate            An additional struct tag whose value is
+                            a Go template, as key=template, e.g.
+                            'validate={{if not .Optional}}required{{end}}'.
+                            If the template's result is empty, the
+                            tag is omitted.  For multiple tags, use
+                            one per tag.
+    -template               A text/template file that is used to
+                            generate the output.
+    -noformat     false     Don't gofmt the output; for templates
+                            that generate something other than Go.
+    -namer        word      How keys are split into words for naming:
+                            'word' splits on underscores, dashes,
+                            dots, spaces, and camelCase; 'underscore'
+                            only splits on underscores.
+    -format       go        The format of the output: 'go' is Go type
+                            definitions; 'jsonschema' is a JSON
+                            Schema, draft 2020-12, of the type.
+    -input-format           The encoding of the input: 'json',
+                            'yaml', 'toml', or 'json5', JSON with
+                            comments, trailing commas, single quotes,
+                            and unquoted keys; 'jsonc' is the same as
+                            'json5'.  If not set, it is detected from
+                            the input file's extension, .yaml, .yml,
+                            .toml, .json5, or .jsonc, otherwise it is
+                            json.  The input is converted to JSON,
+                            which is what -writejson writes; for YAML
+                            and TOML, a yaml or toml tag key is added.
+    -path                   A JSON Pointer, RFC 6901, to the node in
+                            the input that the types are generated
+                            from, e.g. /data/items for an envelope.
+                            A * token matches every element of an
+                            array or value of an object, and each
+                            match is a sample.  -writejson still
+                            writes the whole input.
+    -from         samples   What the input is: 'samples' is JSON that
+                            is a sample, or an array of samples, of
+                            the type; 'schema' is a JSON Schema,
+                            draft-07 or 2020-12, that declares it;
+                            'openapi' is an OpenAPI 3 document, in
+                            JSON, with component schemas; 'har' is an
+                            HTTP Archive, e.g. recorded browser or
+                            proxy traffic, and a type is generated
+                            for the successful JSON responses of each
+                            endpoint, named from its method and path,
+                            e.g. GET /users/{id} is
+                            GetUsersIDResponse.
+    -split        false     With -from har, write the types of each
+                            endpoint to its own file, named after its
+                            type, e.g. get_users_id_response.go, in
+                            the -output directory.
+    -pages        false     Generate a generic Page[T] type for the
+                            pagination envelope, e.g. data, an array
+                            of the items, next_cursor, and total,
+                            that types share, with each of them being
+                            an alias of a Page of its items, e.g.
+                            type GetUsersResponse = Page[User].  If
+                            -pagekeys isn't set, the envelope is
+                            learned: at least two types must have
+                            the same keys.  Only for Go output.
+    -pagekeys               The keys of the pagination envelope,
+                            separated by commas, e.g.
+                            data,next_cursor,total; the first is the
+                            key of the items.  Implies -pages.
+    -component              The name of an OpenAPI component schema to
+                            generate a type for.  For multiple
+                            components, use one per component.  If
+                            not set, types are generated for all of
+                            them.
+    -operations   false     Generate types for the request and
+                            response bodies of each OpenAPI operation,
+                            named <OperationID>Request and
+                            <OperationID>Response.
+    -verify       false     Type-check the generated code and check
+                            that every sample in the JSON decodes into
+                            the generated types, reporting unknown
+                            fields and type mismatches.  Nothing is
+                            written if verification fails.
+    -gentest      false     Generate a _test.go file, next to the
+                            output, that decodes the source JSON into
+                            the type, with unknown fields disallowed,
+                            encodes it, and checks that the result is
+                            the same JSON.  The JSON is embedded in
+                            the test, unless -writejson is used.  The
+                            output must be a file.
+    -comments     false     Add a comment to each field with an example
+                            value, the JSON path of its key, the number
+                            of samples the key was present in, and the
+                            observed range of its numbers or lengths.
+    -order        alpha     The order of the fields within each struct:
+                            'alpha' sorts them by key, 'source' keeps
+                            the order of the keys in the JSON, and
+                            'required' puts keys present in every
+                            sample first, in source order.
+    -typeorder    breadth   The order of the nested type declarations:
+                            'breadth' declares each level of nesting
+                            after the one before it, 'depth' declares
+                            each type right after its parent, and
+                            'alpha' sorts them by name.
+    -initialism             An initialism, e.g. SKU, that should be
+                            uppercased when it is a word in a name.
+                            For multiple initialisms, use one per
+                            initialism.
+    -noinitialism           A common initialism, e.g. ID, that should
+                            not be uppercased.  For multiple
+                            initialisms, use one per initialism.
+    -irregular              An irregular plural, as singular:plural,
+                            e.g. cactus:cacti, used when naming the
+                            element types of arrays.  For multiple
+                            plurals, use one per plural.
 `
 	fmt.Println(helpText)
 }
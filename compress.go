@@ -0,0 +1,132 @@
+package json2go
+
+import (
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// Compression is the compression of the input.
+type Compression int
+
+const (
+	// DetectCompression detects the compression of the input from its
+	// magic bytes; input that doesn't start with any of them isn't
+	// compressed.  Since text can start with them too, e.g. the YAML
+	// x^y: 1, text that doesn't decompress is treated as uncompressed.
+	// This is the default.
+	DetectCompression Compression = iota
+	// Uncompressed is input that isn't compressed.
+	Uncompressed
+	// Gzip is gzip compressed input.
+	Gzip
+	// Zlib is zlib compressed input.
+	Zlib
+	// Bzip2 is bzip2 compressed input.
+	Bzip2
+)
+
+func (c Compression) String() string {
+	switch c {
+	case DetectCompression:
+		return "detect"
+	case Uncompressed:
+		return "uncompressed"
+	case Gzip:
+		return "gzip"
+	case Zlib:
+		return "zlib"
+	case Bzip2:
+		return "bzip2"
+	}
+	return fmt.Sprintf("Compression(%d)", int(c))
+}
+
+// compressionExts are the extensions of compressed files.
+var compressionExts = map[string]Compression{
+	".gz":   Gzip,
+	".gzip": Gzip,
+	".zz":   Zlib,
+	".zlib": Zlib,
+	".bz2":  Bzip2,
+}
+
+// CompressionOf returns the Compression of the file at path, from its
+// extension: .gz and .gzip are gzip, .zz and .zlib are zlib, and .bz2 is
+// bzip2.  If the extension isn't one of them, false is returned.
+func CompressionOf(path string) (Compression, bool) {
+	c, ok := compressionExts[strings.ToLower(filepath.Ext(path))]
+	return c, ok
+}
+
+// trimCompressionExt returns the path without its compression extension, if
+// it has one, e.g. data.yaml for data.yaml.gz.
+func trimCompressionExt(path string) string {
+	if _, ok := CompressionOf(path); ok {
+		return strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	return path
+}
+
+// detectCompression returns the compression of the data from its magic
+// bytes: 1f 8b for gzip, 78 followed by 01, 5e, 9c, or da for zlib, and
+// BZh followed by the block size, 1-9, for bzip2.
+func detectCompression(data []byte) Compression {
+	switch {
+	case len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b:
+		return Gzip
+	case len(data) >= 2 && data[0] == 0x78 && bytes.IndexByte([]byte{0x01, 0x5e, 0x9c, 0xda}, data[1]) >= 0:
+		return Zlib
+	case len(data) >= 4 && bytes.HasPrefix(data, []byte("BZh")) && data[3] >= '1' && data[3] <= '9':
+		return Bzip2
+	}
+	return Uncompressed
+}
+
+// decompress returns the data, which is compressed with c, decompressed.  If
+// c is DetectCompression, the compression is detected from the data and, if
+// the data is UTF-8 text that doesn't decompress, it is returned as is, as
+// uncompressed.  The compression that was used is also returned.
+func (c Compression) decompress(data []byte) ([]byte, Compression, error) {
+	if c != DetectCompression {
+		return c.decompressWith(data)
+	}
+	b, c, err := detectCompression(data).decompressWith(data)
+	if err != nil && utf8.Valid(data) {
+		return data, Uncompressed, nil
+	}
+	return b, c, err
+}
+
+// decompressWith returns the data, which is compressed with c, decompressed.
+func (c Compression) decompressWith(data []byte) ([]byte, Compression, error) {
+	var r io.Reader
+	var err error
+	switch c {
+	case Uncompressed:
+		return data, c, nil
+	case Gzip:
+		r, err = gzip.NewReader(bytes.NewReader(data))
+	case Zlib:
+		r, err = zlib.NewReader(bytes.NewReader(data))
+	case Bzip2:
+		r = bzip2.NewReader(bytes.NewReader(data))
+	default:
+		return nil, c, fmt.Errorf("unknown compression %d", c)
+	}
+	if err != nil {
+		return nil, c, fmt.Errorf("%s input: %s", c, err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, c, fmt.Errorf("%s input: %s", c, err)
+	}
+	return b, c, nil
+}
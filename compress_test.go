@@ -0,0 +1,153 @@
+package json2go
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"strings"
+	"testing"
+)
+
+// bzip2User is {"id": 1, "name": "ann"}, bzip2 compressed; the standard
+// library can't compress bzip2.
+var bzip2User = []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xa2\xd0\x05\x67\x00\x00\x0b\xd9\x80\x00\x10\x50\x04\x20\x10\x26\x23\x00\x0a\x20\x00\x31\x00\x00\x0a\x79\x43\x46\x47\xea\x84\x69\x4c\xc0\x8a\x75\x6c\x2f\x0c\xcb\x63\xf8\xbb\x92\x29\xc2\x84\x85\x16\x80\x2b\x38")
+
+func TestCompression(t *testing.T) {
+	sample := []byte(`{"id": 1, "name": "ann"}`)
+	var gz, zl bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write(sample)
+	w.Close()
+	zw := zlib.NewWriter(&zl)
+	zw.Write(sample)
+	zw.Close()
+	expected := "package main\n\ntype User struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n}\n"
+	expectedJSON := "{\n\t\"id\": 1,\n\t\"name\": \"ann\"\n}\n"
+	tests := []struct {
+		name        string
+		compression Compression
+		input       []byte
+		json        string
+	}{
+		{"gzip", DetectCompression, gz.Bytes(), expectedJSON},
+		{"zlib", DetectCompression, zl.Bytes(), expectedJSON},
+		{"bzip2", DetectCompression, bzip2User, expectedJSON},
+		{"gzip set", Gzip, gz.Bytes(), expectedJSON},
+		{"uncompressed", DetectCompression, sample, string(sample)},
+	}
+	for _, test := range tests {
+		var buff, jsn bytes.Buffer
+		calvin := NewTransmogrifier("user", bytes.NewReader(test.input), &buff)
+		calvin.Compression = test.compression
+		calvin.FieldOrder = SourceOrder
+		calvin.WriteJSON = true
+		calvin.SetJSONWriter(&jsn)
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if buff.String() != expected {
+			t.Errorf("%s: got %q want %q", test.name, buff.String(), expected)
+		}
+		if jsn.String() != test.json {
+			t.Errorf("%s: JSON: got %q want %q", test.name, jsn.String(), test.json)
+		}
+	}
+}
+
+func TestCompressedYAML(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	io.WriteString(w, "id: 1\nname: ann\n")
+	w.Close()
+	expected := "package main\n\ntype User struct {\n\tID   int    `json:\"id\" yaml:\"id\"`\n\tName string `json:\"name\" yaml:\"name\"`\n}\n"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("user", bytes.NewReader(gz.Bytes()), &buff)
+	calvin.InputFormat = YAMLInput
+	calvin.FieldOrder = SourceOrder
+	err := calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+// TestDetectedCompressionText tests that text that starts with a magic number,
+// but isn't compressed, is decoded as is.
+func TestDetectedCompressionText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"zlib", "x^y: 1\n", "package main\n\ntype Config struct {\n\tXY int `json:\"x^y\" yaml:\"x^y\"`\n}\n"},
+		{"bzip2", "BZh1: 1\n", "package main\n\ntype Config struct {\n\tBZh1 int `json:\"BZh1\" yaml:\"BZh1\"`\n}\n"},
+	}
+	for _, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("config", strings.NewReader(test.input), &buff)
+		calvin.InputFormat = YAMLInput
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if buff.String() != test.expected {
+			t.Errorf("%s: got %q want %q", test.name, buff.String(), test.expected)
+		}
+	}
+}
+
+func TestCompressionErrors(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte(`{"id": 1}`))
+	w.Close()
+	tests := []struct {
+		name        string
+		compression Compression
+		input       []byte
+		err         string
+	}{
+		{"truncated", DetectCompression, gz.Bytes()[:gz.Len()-6], "gzip input: unexpected EOF"},
+		{"not gzip", Gzip, []byte(`{"id": 1, "name": "ann"}`), "gzip input: gzip: invalid header"},
+		{"not zlib", Zlib, []byte(`{"id": 1}`), "zlib input: zlib: invalid header"},
+	}
+	for _, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("user", bytes.NewReader(test.input), &buff)
+		calvin.Compression = test.compression
+		err := calvin.Gen()
+		if err == nil {
+			t.Errorf("%s: expected an error, got none", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got %q want %q", test.name, err, test.err)
+		}
+	}
+}
+
+func TestCompressionOf(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Compression
+		ok       bool
+	}{
+		{"orders.json.gz", Gzip, true},
+		{"orders.JSON.GZ", Gzip, true},
+		{"orders.zlib", Zlib, true},
+		{"orders.json.bz2", Bzip2, true},
+		{"orders.json", DetectCompression, false},
+	}
+	for _, test := range tests {
+		c, ok := CompressionOf(test.path)
+		if c != test.expected || ok != test.ok {
+			t.Errorf("%s: got %s, %t want %s, %t", test.path, c, ok, test.expected, test.ok)
+		}
+	}
+}
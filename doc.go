@@ -0,0 +1,118 @@
+package json2go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxExampleLen is the maximum number of runes of an example string that are
+// included in a field's comment; longer strings are truncated.
+const maxExampleLen = 40
+
+// rootPath returns the JSON path of the type being generated from the decoded
+// JSON: $, or $[*] if the JSON is an array of samples.
+func rootPath(def interface{}) string {
+	if _, ok := def.([]interface{}); ok {
+		return "$[*]"
+	}
+	return "$"
+}
+
+// jsonPath returns the JSON path of key within the object at path.  Keys
+// that aren't identifiers use bracket notation, e.g. $["first name"].
+func jsonPath(path, key string) string {
+	if isPathIdent(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// arrayPath returns the JSON path of the elements of the, possibly nested,
+// array at path.
+func arrayPath(path string, t *jsonType) string {
+	for ; t != nil && t.kind == arrayKind; t = t.elem {
+		path += "[*]"
+	}
+	return path
+}
+
+// isPathIdent returns whether the key can be used in dot notation.
+func isPathIdent(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		if r == '_' || unicode.IsLetter(r) || (i > 0 && unicode.IsDigit(r)) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// fieldDoc returns the comment for a field of the parent object: an example
+// value, the field's JSON path, how many of the parent's objects the key was
+// present in, and the observed range of its numbers or lengths, e.g.
+//
+//	example: "towel"; path: $.name; present: 3/4 (75%); length: 5-9
+//
+// For declared types, the presence is omitted and the description, if any,
+// is the first line of the comment.
+func fieldDoc(f *field, parent *jsonType, path string) string {
+	var desc string
+	if f.typ.desc != "" {
+		desc = strings.Join(strings.Fields(f.typ.desc), " ") + "\n"
+	}
+	var parts []string
+	if ex := exampleString(f.typ); ex != "" {
+		parts = append(parts, "example: "+ex)
+	}
+	parts = append(parts, "path: "+path)
+	if parent.objects > 0 && !parent.declared {
+		parts = append(parts, fmt.Sprintf("present: %d/%d (%d%%)", f.count, parent.objects, f.count*100/parent.objects))
+	}
+	if f.typ.nums > 0 {
+		parts = append(parts, "range: "+formatRange(strconv.FormatFloat(f.typ.min, 'g', -1, 64), strconv.FormatFloat(f.typ.max, 'g', -1, 64)))
+	}
+	if f.typ.lens > 0 {
+		parts = append(parts, "length: "+formatRange(strconv.Itoa(f.typ.minLen), strconv.Itoa(f.typ.maxLen)))
+	}
+	return desc + strings.Join(parts, "; ")
+}
+
+// formatRange returns min-max, or just min if they are the same.
+func formatRange(min, max string) string {
+	if min == max {
+		return min
+	}
+	return min + "-" + max
+}
+
+// exampleString returns the example value of t, or of the elements of an
+// array, formatted as JSON.  Strings longer than maxExampleLen runes are
+// truncated.  An empty string is returned if there is no example.
+func exampleString(t *jsonType) string {
+	for t != nil && t.kind == arrayKind {
+		t = t.elem
+	}
+	if t == nil {
+		return ""
+	}
+	switch v := t.example.(type) {
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case string:
+		if utf8.RuneCountInString(v) > maxExampleLen {
+			v = string([]rune(v)[:maxExampleLen]) + "…"
+		}
+		// the example is within a line comment, so it can't contain
+		// line breaks
+		return strconv.Quote(v)
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package json2go
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		key      string
+		expected string
+	}{
+		{"$", "name", "$.name"},
+		{"$", "first_name", "$.first_name"},
+		{"$[*]", "id", "$[*].id"},
+		{"$", "first name", `$["first name"]`},
+		{"$", "1st", `$["1st"]`},
+		{"$", "", `$[""]`},
+		{"$.a", `q"uote`, `$.a["q\"uote"]`},
+	}
+	for _, test := range tests {
+		p := jsonPath(test.path, test.key)
+		if p != test.expected {
+			t.Errorf("%s %q: got %s want %s", test.path, test.key, p, test.expected)
+		}
+	}
+}
+
+func TestExampleString(t *testing.T) {
+	tests := []struct {
+		vals     []interface{}
+		expected string
+	}{
+		{[]interface{}{nil, "first", "second"}, `"first"`},
+		{[]interface{}{42.0}, "42"},
+		{[]interface{}{1.5}, "1.5"},
+		{[]interface{}{true}, "true"},
+		{[]interface{}{"line\nbreak"}, `"line\nbreak"`},
+		{[]interface{}{[]interface{}{"elem"}}, `"elem"`},
+		{[]interface{}{map[string]interface{}{"a": 1.0}}, ""},
+		{[]interface{}{nil}, ""},
+		{[]interface{}{strings.Repeat("x", 50)}, `"` + strings.Repeat("x", maxExampleLen) + `…"`},
+	}
+	for i, test := range tests {
+		ex := exampleString(inferType(test.vals, nil))
+		if ex != test.expected {
+			t.Errorf("%d: got %s want %s", i, ex, test.expected)
+		}
+	}
+}
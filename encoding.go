@@ -0,0 +1,116 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// InputFormat is the encoding of the input.
+type InputFormat int
+
+const (
+	// JSONInput is JSON; this is the default.
+	JSONInput InputFormat = iota
+	// YAMLInput is YAML.
+	YAMLInput
+	// TOMLInput is TOML.  Unlike JSON, TOML has datetimes: an offset
+	// datetime is a time.Time.
+	TOMLInput
+	// JSON5Input is lenient JSON, JSON5 or JSONC: comments, trailing
+	// commas, single-quoted strings, and unquoted keys are accepted.
+	JSON5Input
+)
+
+// ParseInputFormat returns the InputFormat for s, which is one of json, yaml,
+// toml, json5, or jsonc, which is the same as json5.
+func ParseInputFormat(s string) (InputFormat, error) {
+	switch strings.ToLower(s) {
+	case "json":
+		return JSONInput, nil
+	case "yaml", "yml":
+		return YAMLInput, nil
+	case "toml":
+		return TOMLInput, nil
+	case "json5", "jsonc":
+		return JSON5Input, nil
+	}
+	return JSONInput, fmt.Errorf("unknown input format %q: expected json, yaml, toml, json5, or jsonc", s)
+}
+
+// InputFormatOf returns the InputFormat of the file at path, from its
+// extension: .yaml and .yml are YAML, .toml is TOML, and .json5 and .jsonc
+// are JSON5.  A compression extension, e.g. .gz, is ignored, so data.yaml.gz
+// is YAML.  If the extension isn't one of them, or .json, false is returned.
+func InputFormatOf(path string) (InputFormat, bool) {
+	switch strings.ToLower(filepath.Ext(trimCompressionExt(path))) {
+	case ".json":
+		return JSONInput, true
+	case ".yaml", ".yml":
+		return YAMLInput, true
+	case ".toml":
+		return TOMLInput, true
+	case ".json5", ".jsonc":
+		return JSON5Input, true
+	}
+	return JSONInput, false
+}
+
+// tagKey returns the key of the struct tag for the input format; JSON input
+// has none as the json tag is always defined.
+func (f InputFormat) tagKey() string {
+	switch f {
+	case YAMLInput:
+		return "yaml"
+	case TOMLInput:
+		return "toml"
+	}
+	return ""
+}
+
+// toJSON returns the data, which is encoded as f, as JSON that is indented
+// with tabs.  The order of the keys is preserved.
+//
+// Each format's decoder decodes the data into the values that are its JSON
+// equivalent: objects are *orderedObjects, to preserve the order of their
+// keys, arrays are []interface{}, and numbers are json.Numbers.
+func (f InputFormat) toJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	var err error
+	switch f {
+	case JSONInput:
+		return data, nil
+	case YAMLInput:
+		v, err = decodeYAML(data)
+	case TOMLInput:
+		v, err = decodeTOML(data)
+	case JSON5Input:
+		v, err = decodeJSON5(data)
+	default:
+		return nil, fmt.Errorf("unknown input format %d", f)
+	}
+	if err != nil {
+		return nil, err
+	}
+	b, err := json.MarshalIndent(v, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// entrySep returns the length of the separator, a comma, that follows an
+// entry of an array or object at the start of s, or 0 if s starts with the
+// closing delimiter, end, instead.
+func entrySep(s string, end byte) (int, error) {
+	switch {
+	case s == "":
+		return 0, fmt.Errorf("expected , or %c, got end of input", end)
+	case s[0] == ',':
+		return 1, nil
+	case s[0] == end:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("expected , or %c, got %q", end, s[0])
+}
@@ -0,0 +1,121 @@
+package json2go
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInputFormat(t *testing.T) {
+	tests := []struct {
+		format   InputFormat
+		input    string
+		tagKeys  []string
+		expected string
+		json     string
+	}{
+		{
+			YAMLInput, "name: svc\nport: 8080\ntls:\n  enabled: true\n", nil,
+			"package main\n\ntype Config struct {\n\tName string `json:\"name\" yaml:\"name\"`\n\tPort int    `json:\"port\" yaml:\"port\"`\n\tTLS  `json:\"tls\" yaml:\"tls\"`\n}\n\ntype TLS struct {\n\tEnabled bool `json:\"enabled\" yaml:\"enabled\"`\n}\n",
+			"{\n\t\"name\": \"svc\",\n\t\"port\": 8080,\n\t\"tls\": {\n\t\t\"enabled\": true\n\t}\n}\n",
+		},
+		{
+			TOMLInput, "name = \"svc\"\nport = 8080\n\n[tls]\nenabled = true\n", nil,
+			"package main\n\ntype Config struct {\n\tName string `json:\"name\" toml:\"name\"`\n\tPort int    `json:\"port\" toml:\"port\"`\n\tTLS  `json:\"tls\" toml:\"tls\"`\n}\n\ntype TLS struct {\n\tEnabled bool `json:\"enabled\" toml:\"enabled\"`\n}\n",
+			"{\n\t\"name\": \"svc\",\n\t\"port\": 8080,\n\t\"tls\": {\n\t\t\"enabled\": true\n\t}\n}\n",
+		},
+		{
+			TOMLInput, "version = 2.0\nport = 8080\nreleased = 1979-05-27T07:32:00-08:00\n", nil,
+			"package main\n\nimport (\n\t\"time\"\n)\n\ntype Config struct {\n\tVersion  float64   `json:\"version\" toml:\"version\"`\n\tPort     int       `json:\"port\" toml:\"port\"`\n\tReleased time.Time `json:\"released\" toml:\"released\"`\n}\n",
+			"{\n\t\"version\": 2.0,\n\t\"port\": 8080,\n\t\"released\": \"1979-05-27T07:32:00-08:00\"\n}\n",
+		},
+		{
+			JSON5Input, "// tsconfig\n{\n\tcompilerOptions: {strict: true,},\n}\n", nil,
+			"package main\n\ntype Config struct {\n\tCompilerOptions `json:\"compilerOptions\"`\n}\n\ntype CompilerOptions struct {\n\tStrict bool `json:\"strict\"`\n}\n",
+			"{\n\t\"compilerOptions\": {\n\t\t\"strict\": true\n\t}\n}\n",
+		},
+		{
+			JSON5Input, "{size: 5., count: 5}\n", nil,
+			"package main\n\ntype Config struct {\n\tSize  float64 `json:\"size\"`\n\tCount int     `json:\"count\"`\n}\n",
+			"{\n\t\"size\": 5.0,\n\t\"count\": 5\n}\n",
+		},
+		{
+			YAMLInput, "max_conns: 1\n", []string{"yaml:kebab,omitempty"},
+			"package main\n\ntype Config struct {\n\tMaxConns int `json:\"max_conns\" yaml:\"max-conns,omitempty\"`\n}\n",
+			"{\n\t\"max_conns\": 1\n}\n",
+		},
+	}
+	for i, test := range tests {
+		var buff, jsn bytes.Buffer
+		calvin := NewTransmogrifier("config", bytes.NewReader([]byte(test.input)), &buff)
+		calvin.InputFormat = test.format
+		calvin.FieldOrder = SourceOrder
+		calvin.WriteJSON = true
+		calvin.SetJSONWriter(&jsn)
+		calvin.Verify = true
+		err := calvin.SetTagKeys(test.tagKeys)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		err = calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if buff.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, buff.String(), test.expected)
+		}
+		if jsn.String() != test.json {
+			t.Errorf("%d: got JSON %q want %q", i, jsn.String(), test.json)
+		}
+	}
+}
+
+func TestParseInputFormat(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected InputFormat
+		err      bool
+	}{
+		{"json", JSONInput, false},
+		{"yaml", YAMLInput, false},
+		{"YML", YAMLInput, false},
+		{"toml", TOMLInput, false},
+		{"json5", JSON5Input, false},
+		{"jsonc", JSON5Input, false},
+		{"xml", JSONInput, true},
+	}
+	for _, test := range tests {
+		f, err := ParseInputFormat(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%s: got %d want %d", test.s, f, test.expected)
+		}
+	}
+}
+
+func TestInputFormatOf(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected InputFormat
+		ok       bool
+	}{
+		{"config.yaml", YAMLInput, true},
+		{"dir/config.YML", YAMLInput, true},
+		{"Cargo.toml", TOMLInput, true},
+		{"data.json", JSONInput, true},
+		{".vscode/settings.jsonc", JSON5Input, true},
+		{"fixtures/config.yaml.gz", YAMLInput, true},
+		{"orders.json.bz2", JSONInput, true},
+		{"stdin", JSONInput, false},
+	}
+	for _, test := range tests {
+		f, ok := InputFormatOf(test.path)
+		if f != test.expected || ok != test.ok {
+			t.Errorf("%s: got %d, %t want %d, %t", test.path, f, ok, test.expected, test.ok)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// defineEnum returns the definition of an enum type: a named string type, a
+// const for each of the values, and an IsValid method.  If StrictEnums is
+// set, an UnmarshalJSON method that rejects any other values is also
+// defined.
+func (t *Transmogrifier) defineEnum(name string, vals []string) TypeDef {
+	var buff bytes.Buffer
+	// the consts are in the same scope as the types, so their names must
+	// be unique among them
+	if t.typeNames == nil {
+		t.typeNames = map[string]struct{}{}
+	}
+	names := enumConstNames(name, vals, t.initialisms, t.typeNames)
+	def := TypeDef{Name: name, Kind: "enum", Type: "string"}
+	for i, v := range vals {
+		def.Values = append(def.Values, EnumValue{Name: names[i], Value: v})
+	}
+	buff.WriteString(fmt.Sprintf("type %s string\n\n", name))
+	buff.WriteString("const (\n")
+	for i, v := range vals {
+		buff.WriteString(fmt.Sprintf("\t%s %s = %q\n", names[i], name, v))
+	}
+	buff.WriteString(")\n\n")
+	buff.WriteString(fmt.Sprintf("// IsValid reports whether v is a known %s value.\n", name))
+	buff.WriteString(fmt.Sprintf("func (v %s) IsValid() bool {\n", name))
+	buff.WriteString(fmt.Sprintf("\tswitch v {\n\tcase %s:\n", strings.Join(names, ", ")))
+	buff.WriteString("\t\treturn true\n\t}\n\treturn false\n}\n\n")
+	if !t.StrictEnums {
+		def.Source = formatSource(buff.Bytes())
+		return def
+	}
+	t.addImport("encoding/json")
+	t.addImport("fmt")
+	def.imports = []string{"encoding/json", "fmt"}
+	buff.WriteString(fmt.Sprintf("// UnmarshalJSON implements json.Unmarshaler.  Values that aren't known\n// %s values are rejected; null is a no-op, as it is for a string.\n", name))
+	buff.WriteString(fmt.Sprintf("func (v *%s) UnmarshalJSON(b []byte) error {\n", name))
+	buff.WriteString("\tif string(b) == \"null\" {\n\t\treturn nil\n\t}\n")
+	buff.WriteString("\tvar s string\n")
+	buff.WriteString("\tif err := json.Unmarshal(b, &s); err != nil {\n\t\treturn err\n\t}\n")
+	buff.WriteString(fmt.Sprintf("\tif !%s(s).IsValid() {\n", name))
+	buff.WriteString(fmt.Sprintf("\t\treturn fmt.Errorf(\"invalid %s value: %%q\", s)\n\t}\n", name))
+	buff.WriteString(fmt.Sprintf("\t*v = %s(s)\n\treturn nil\n}\n\n", name))
+	def.Source = formatSource(buff.Bytes())
+	return def
+}
+
+// enumConstNames returns the const names for the values of the enum.  Each
+// name is the enum's name followed by the value in MixedCase; runes that
+// aren't letters or digits are treated as word separators.  Values that
+// result in an empty name get the Empty suffix and names that are already in
+// used, e.g. the name of a type, get a numeric suffix.  The names are added to
+// used.
+func enumConstNames(name string, vals []string, in Initialisms, used map[string]struct{}) []string {
+	names := make([]string, len(vals))
+	for i, v := range vals {
+		words := strings.FieldsFunc(v, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		n := name
+		for _, w := range words {
+			n += in.upper(strings.Title(w))
+		}
+		if n == name {
+			n += "Empty"
+		}
+		names[i] = uniqueName(n, used)
+	}
+	return names
+}
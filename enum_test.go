@@ -0,0 +1,103 @@
+package json2go
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEnumConstNames(t *testing.T) {
+	tests := []struct {
+		vals     []string
+		used     []string
+		expected []string
+	}{
+		{[]string{"active", "in_progress"}, nil, []string{"StatusActive", "StatusInProgress"}},
+		{[]string{"in-progress", "in progress"}, nil, []string{"StatusInProgress", "StatusInProgress2"}},
+		{[]string{"", "id", "1st"}, nil, []string{"StatusEmpty", "StatusID", "Status1st"}},
+		{[]string{"a", "b"}, []string{"Status", "StatusA"}, []string{"StatusA2", "StatusB"}},
+	}
+	for i, test := range tests {
+		used := map[string]struct{}{}
+		for _, n := range test.used {
+			used[n] = struct{}{}
+		}
+		names := enumConstNames("Status", test.vals, nil, used)
+		if len(names) != len(test.expected) {
+			t.Errorf("%d: got %d names, want %d", i, len(names), len(test.expected))
+			continue
+		}
+		for j, name := range names {
+			if name != test.expected[j] {
+				t.Errorf("%d: got %q, want %q", i, name, test.expected[j])
+			}
+		}
+	}
+}
+
+// TestStrictEnumNull runs the round-trip test generated for a strict enum
+// that is null in a sample; a null must decode, as it does into a string.
+func TestStrictEnumNull(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping go test of the generated code in short mode")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go isn't in the PATH")
+	}
+	var buff, tbuff bytes.Buffer
+	calvin := NewTransmogrifier("item", strings.NewReader(`[{"status":"active"},{"status":null},{"status":"deleted"}]`), &buff)
+	calvin.EnumThreshold = 3
+	calvin.StrictEnums = true
+	calvin.Verify = true
+	calvin.GenTest = true
+	calvin.SetTestWriter(&tbuff)
+	err = calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	dir, err := ioutil.TempDir("", "json2go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string][]byte{
+		"go.mod":       []byte("module enumtest\n\ngo 1.18\n"),
+		"item.go":      buff.Bytes(),
+		"item_test.go": tbuff.Bytes(),
+	}
+	for name, b := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), b, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goTool, "test", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GO111MODULE=on", "GOFLAGS=-mod=mod")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Errorf("generated test failed: %s\n%s", err, out)
+	}
+}
+
+// TestEnumConstTypeNames tests that an enum's consts and the types don't have
+// the same names.
+func TestEnumConstTypeNames(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\tStatus  Status   `json:\"status\"`\n\tStatusA StatusA2 `json:\"status_a\"`\n}\n\ntype Status string\n\nconst (\n\tStatusA Status = \"a\"\n\tStatusB Status = \"b\"\n)\n\n// IsValid reports whether v is a known Status value.\nfunc (v Status) IsValid() bool {\n\tswitch v {\n\tcase StatusA, StatusB:\n\t\treturn true\n\t}\n\treturn false\n}\n\ntype StatusA2 struct {\n\tX int `json:\"x\"`\n}\n"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", strings.NewReader(`[{"status":"a","status_a":{"x":1}},{"status":"b","status_a":{"x":2}}]`), &buff)
+	calvin.EnumThreshold = 3
+	calvin.Verify = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
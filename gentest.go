@@ -0,0 +1,184 @@
+package json2go
+
+import (
+	"bytes"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// testTemplate is the round-trip test that is generated for the type: the
+// source JSON is decoded, rejecting unknown fields, encoded, and compared to
+// the source.  Since keys that were missing from the source are encoded with
+// their zero value, a key that is only in the encoded JSON, or is null in the
+// source, is equal if its value is the zero value.
+const testTemplate = `// Code generated by json2go. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"bytes"
+	"encoding/json"
+{{- if .File}}
+	"io/ioutil"
+{{- end}}
+	"reflect"
+	"testing"
+)
+{{if not .File}}
+var {{.Var}} = []byte({{.JSON}})
+{{end}}
+func Test{{.Name}}RoundTrip(t *testing.T) {
+{{- if .File}}
+	{{.Var}}, err := ioutil.ReadFile({{printf "%q" .File}})
+	if err != nil {
+		t.Fatal(err)
+	}
+{{- end}}
+	dec := json.NewDecoder(bytes.NewReader({{.Var}}))
+	dec.DisallowUnknownFields()
+	var v {{.Type}}
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("decode: %s", err)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encode: %s", err)
+	}
+	var want, got interface{}
+	if err := json.Unmarshal({{.Var}}, &want); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !equal{{.Name}}JSON(want, got) {
+		t.Errorf("round trip mismatch:\nwant %s\ngot  %s", {{.Var}}, b)
+	}
+}
+
+// equal{{.Name}}JSON returns whether the decoded JSON values are semantically
+// equal; a null, or missing, value equals a zero value.
+func equal{{.Name}}JSON(want, got interface{}) bool {
+	switch w := want.(type) {
+	case nil:
+		return isZero{{.Name}}JSON(got)
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !equal{{.Name}}JSON(v, g[k]) {
+				return false
+			}
+		}
+		for k, v := range g {
+			if _, ok := w[k]; !ok && !isZero{{.Name}}JSON(v) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(w) != len(g) {
+			return len(w) == 0 && got == nil
+		}
+		for i := range w {
+			if !equal{{.Name}}JSON(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+// isZero{{.Name}}JSON returns whether the decoded JSON value is what a Go
+// zero value is encoded as.
+func isZero{{.Name}}JSON(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !v
+	case float64:
+		return v == 0
+	case string:
+		return v == ""
+	case map[string]interface{}:
+		for _, e := range v {
+			if !isZero{{.Name}}JSON(e) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+`
+
+// testModel is what testTemplate is executed with.
+type testModel struct {
+	Package string
+	// Name is the name of the type being tested.
+	Name string
+	// Type is the type the source JSON is decoded into.
+	Type string
+	// Var is the name of the variable that holds the source JSON.
+	Var string
+	// JSON is the source JSON as a Go string literal; it is only used if
+	// File is empty.
+	JSON string
+	// File is the path of the source JSON file, relative to the package.
+	File string
+}
+
+// SetTestWriter sets the writer to which the round-trip test is written when
+// GenTest is true.
+func (t *Transmogrifier) SetTestWriter(w io.Writer) {
+	t.tw = w
+}
+
+// SetTestJSONFile sets the path, relative to the generated package, of the
+// file that the round-trip test reads the source JSON from, e.g. the file
+// that WriteJSON writes to.  If it isn't set, the source JSON is embedded in
+// the test.
+func (t *Transmogrifier) SetTestJSONFile(path string) {
+	t.testJSONFile = path
+}
+
+// genTest returns the round-trip test for the type, name, that the source
+// JSON, src, was decoded into.  If the source is an array, it is decoded
+// into a slice of the type.  If a path was set, src is the node it selected,
+// which is embedded in the test.
+func (t *Transmogrifier) genTest(name string, src []byte, isArray bool) ([]byte, error) {
+	m := testModel{Package: t.pkg, Name: name, Type: name, Var: "json" + name, File: t.testJSONFile}
+	if isArray {
+		m.Type = "[]" + name
+	}
+	// the JSON file is the whole input, not the node a path selected
+	if t.path != nil {
+		m.File = ""
+	}
+	if m.File == "" {
+		m.JSON = goStringLit(src)
+	}
+	var buff bytes.Buffer
+	err := template.Must(template.New("test").Parse(testTemplate)).Execute(&buff, m)
+	if err != nil {
+		return nil, err
+	}
+	return format.Source(buff.Bytes())
+}
+
+// goStringLit returns s as a Go string literal: a raw string, unless s
+// contains a backquote or carriage return.
+func goStringLit(s []byte) string {
+	if bytes.ContainsAny(s, "`\r") {
+		return strconv.Quote(string(s))
+	}
+	return "`" + strings.TrimRight(string(s), "\n") + "`"
+}
@@ -0,0 +1,50 @@
+package json2go
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenTest(t *testing.T) {
+	tests := []struct {
+		json     string
+		file     string
+		expected []string
+	}{
+		{`{"id": 1}`, "", []string{"func TestTestRoundTrip(", "var jsonTest = []byte(`{\"id\": 1}`)", "var v Test\n", "dec.DisallowUnknownFields()"}},
+		{`[{"id": 1}, {"id": 2}]`, "", []string{"var v []Test\n"}},
+		{"{\"id\": \"`\"}", "", []string{"var jsonTest = []byte(\"{\\\"id\\\": \\\"`\\\"}\")"}},
+		{`{"id": 1}`, "test.json", []string{`jsonTest, err := ioutil.ReadFile("test.json")`, `"io/ioutil"`}},
+	}
+	for i, test := range tests {
+		var buff, tbuff bytes.Buffer
+		calvin := NewTransmogrifier("test", strings.NewReader(test.json), &buff)
+		calvin.GenTest = true
+		calvin.SetTestWriter(&tbuff)
+		calvin.SetTestJSONFile(test.file)
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		src := tbuff.String()
+		_, err = parser.ParseFile(token.NewFileSet(), "test_test.go", src, 0)
+		if err != nil {
+			t.Errorf("%d: generated test doesn't parse: %s", i, err)
+		}
+		for _, s := range test.expected {
+			if !strings.Contains(src, s) {
+				t.Errorf("%d: expected the test to contain %q, got %q", i, s, src)
+			}
+		}
+	}
+	// a test writer is required
+	calvin := NewTransmogrifier("test", strings.NewReader(`{"id": 1}`), &bytes.Buffer{})
+	calvin.GenTest = true
+	if err := calvin.Gen(); err == nil {
+		t.Error("expected an error without a test writer, got none")
+	}
+}
@@ -0,0 +1,285 @@
+package json2go
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// harEndpoint is an endpoint, a method and normalized URL path, of the
+// responses recorded in a HAR file.
+type harEndpoint struct {
+	method string
+	// path is the normalized URL path, e.g. /users/{id}.
+	path string
+	// samples are the bodies of the endpoint's responses; array bodies
+	// contribute each of their elements.
+	samples []interface{}
+	orders  []*keyOrder
+	// bodies are the JSON bodies of the endpoint's responses.
+	bodies [][]byte
+	// isArray is true if any of the bodies was an array.
+	isArray bool
+}
+
+// String returns the endpoint, e.g. GET /users/{id}.
+func (e *harEndpoint) String() string {
+	return e.method + " " + e.path
+}
+
+// typeName returns the name of the endpoint's response type, before it is
+// made an identifier by a Namer: its method, the segments of its path, and
+// response, separated by underscores, e.g. get_users_id_response.
+func (e *harEndpoint) typeName() string {
+	words := []string{strings.ToLower(e.method)}
+	for _, seg := range strings.Split(e.path, "/") {
+		seg = strings.Trim(seg, "{}")
+		if seg != "" {
+			words = append(words, seg)
+		}
+	}
+	return strings.Join(append(words, "response"), "_")
+}
+
+// resource returns the last segment of the endpoint's path that isn't an ID,
+// e.g. posts for /users/{id}/posts, or an empty string if there isn't one.
+func (e *harEndpoint) resource() string {
+	segs := strings.Split(e.path, "/")
+	for i := len(segs) - 1; i >= 0; i-- {
+		if segs[i] != "" && segs[i] != "{id}" {
+			return segs[i]
+		}
+	}
+	return ""
+}
+
+// harEndpoints returns the endpoints of the successful, 2xx, responses with
+// JSON content that were recorded in the decoded HAR file, in the order that
+// they were first recorded.  Responses whose content can't be decoded, e.g.
+// because it was truncated, are skipped.
+func harEndpoints(doc interface{}) ([]*harEndpoint, error) {
+	m, _ := doc.(map[string]interface{})
+	log, ok := m["log"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid HAR: expected an object with a log")
+	}
+	entries, ok := log["entries"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid HAR: expected the log to have entries")
+	}
+	var endpoints []*harEndpoint
+	byName := map[string]*harEndpoint{}
+	for i, v := range entries {
+		entry, _ := v.(map[string]interface{})
+		req, _ := entry["request"].(map[string]interface{})
+		resp, _ := entry["response"].(map[string]interface{})
+		if req == nil || resp == nil {
+			return nil, fmt.Errorf("invalid HAR: entry %d: expected a request and a response", i)
+		}
+		status, _ := resp["status"].(float64)
+		if status < 200 || status > 299 {
+			continue
+		}
+		body, ok := harContent(resp)
+		if !ok {
+			continue
+		}
+		val, order, err := decodeOrdered(body)
+		if err != nil {
+			continue
+		}
+		method, _ := req["method"].(string)
+		rawURL, _ := req["url"].(string)
+		p, err := harPath(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HAR: entry %d: %s", i, err)
+		}
+		e := &harEndpoint{method: strings.ToUpper(method), path: p}
+		if byName[e.String()] == nil {
+			byName[e.String()] = e
+			endpoints = append(endpoints, e)
+		}
+		e = byName[e.String()]
+		e.bodies = append(e.bodies, body)
+		samples, orders := getSamples(val, order)
+		e.samples = append(e.samples, samples...)
+		e.orders = append(e.orders, orders...)
+		if _, ok := val.([]interface{}); ok {
+			e.isArray = true
+		}
+	}
+	return endpoints, nil
+}
+
+// harContent returns the text of the response's content if it is JSON.
+func harContent(resp map[string]interface{}) ([]byte, bool) {
+	content, _ := resp["content"].(map[string]interface{})
+	mimeType, _ := content["mimeType"].(string)
+	mimeType = strings.TrimSpace(strings.SplitN(mimeType, ";", 2)[0])
+	if !strings.HasSuffix(mimeType, "/json") && !strings.HasSuffix(mimeType, "+json") {
+		return nil, false
+	}
+	text, _ := content["text"].(string)
+	if enc, _ := content["encoding"].(string); enc == "base64" {
+		b, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return nil, false
+		}
+		return b, len(b) > 0
+	}
+	return []byte(text), text != ""
+}
+
+// harPath returns the normalized path of the URL: the segments that are
+// identifiers, e.g. 42 or a UUID, are replaced with {id}, so that the
+// responses for different resources are samples of the same endpoint.
+func harPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	segs := strings.Split(strings.Trim(path.Clean("/"+u.Path), "/"), "/")
+	for i, seg := range segs {
+		if isPathID(seg) {
+			segs[i] = "{id}"
+		}
+	}
+	return "/" + strings.Join(segs, "/"), nil
+}
+
+// isPathID returns whether the path segment is an identifier: a number, a
+// UUID, or a hexadecimal string of at least 16 characters, e.g. a hash or an
+// object ID.
+func isPathID(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	var digits, hex int
+	for _, r := range seg {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F':
+			hex++
+		case r == '-':
+		default:
+			return false
+		}
+	}
+	switch {
+	case digits == len(seg):
+		return true
+	case len(seg) == 36 && strings.Count(seg, "-") == 4:
+		return seg[8] == '-' && seg[13] == '-' && seg[18] == '-' && seg[23] == '-'
+	}
+	return digits > 0 && digits+hex == len(seg) && len(seg) >= 16
+}
+
+// harRoot is the type generated for a HAR endpoint.
+type harRoot struct {
+	// endpoint is the endpoint, e.g. GET /users/{id}.
+	endpoint string
+	// name is the name of the endpoint's type.
+	name string
+	// bodies are the JSON bodies of the endpoint's responses, which are
+	// samples of its type.
+	bodies [][]byte
+}
+
+// SetEndpointWriter sets the function that returns the writer to which the
+// types of each endpoint, when the input is a HAR file, are written.  It is
+// called with the name of the endpoint's type, e.g. GetUsersIDResponse.  If
+// it isn't set, the types of all of the endpoints are written to the output
+// writer.
+func (t *Transmogrifier) SetEndpointWriter(f func(name string) (io.Writer, error)) {
+	t.ew = f
+}
+
+// writeEndpoints writes the types of each endpoint, the ones reachable from
+// its root type, to the endpoint's writer.  Every endpoint is rendered before
+// any of them are written.
+func (t *Transmogrifier) writeEndpoints(m Model, roots []harRoot) error {
+	written := map[string]bool{}
+	srcs := make([][]byte, len(roots))
+	for i, r := range roots {
+		b, err := t.render(endpointModel(m, r.name, t.ImportJSON, written))
+		if err != nil {
+			return err
+		}
+		srcs[i] = b
+	}
+	for i, r := range roots {
+		b := srcs[i]
+		w, err := t.ew(r.name)
+		if err != nil {
+			return err
+		}
+		n, err := w.Write(b)
+		if err != nil {
+			return err
+		}
+		if n != len(b) {
+			return ShortWriteError{n: len(b), written: n, operation: "generated code for " + r.endpoint}
+		}
+	}
+	return nil
+}
+
+// endpointModel returns the model for the type named root: the type
+// definitions that are reachable from it, in the order that they are in m,
+// and the imports that they use, along with encoding/json if importJSON is
+// set.  Types that are in written, e.g. a Page shared by the endpoints, are
+// skipped, as they are already in another endpoint's file; the ones that
+// aren't are added to it.
+func endpointModel(m Model, root string, importJSON bool, written map[string]bool) Model {
+	byName := make(map[string]TypeDef, len(m.Types))
+	for _, def := range m.Types {
+		byName[def.Name] = def
+	}
+	// the enums of a struct are the ones defined after it, see
+	// orderTypeDefs; they're kept with it
+	enums := map[string][]string{}
+	var last string
+	for _, def := range m.Types {
+		if def.Kind == "enum" && last != "" {
+			enums[last] = append(enums[last], def.Name)
+			continue
+		}
+		last = def.Name
+	}
+	keep := map[string]bool{}
+	var visit func(name string)
+	visit = func(name string) {
+		if keep[name] {
+			return
+		}
+		keep[name] = true
+		for _, e := range enums[name] {
+			keep[e] = true
+		}
+		for _, c := range byName[name].children {
+			visit(c)
+		}
+	}
+	visit(root)
+	sub := Model{Name: root, Package: m.Package}
+	imports := map[string]bool{"encoding/json": importJSON}
+	for _, def := range m.Types {
+		if keep[def.Name] && !written[def.Name] {
+			written[def.Name] = true
+			sub.Types = append(sub.Types, def)
+			for _, imp := range def.imports {
+				imports[imp] = true
+			}
+		}
+	}
+	for _, imp := range m.Imports {
+		if imports[imp] {
+			sub.Imports = append(sub.Imports, imp)
+		}
+	}
+	return sub
+}
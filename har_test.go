@@ -0,0 +1,206 @@
+package json2go
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var harDoc = []byte(`{
+	"log": {
+		"version": "1.2",
+		"entries": [
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/users/1?expand=team"},
+				"response": {"status": 200, "content": {"mimeType": "application/json; charset=utf-8", "text": "{\"id\": 1, \"name\": \"ann\", \"team\": {\"id\": 7, \"name\": \"ops\"}}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/users/2"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "encoding": "base64", "text": "eyJpZCI6IDIsICJuYW1lIjogImJvYiIsICJlbWFpbCI6ICJib2JAZXhhbXBsZS5jb20ifQ=="}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/users/3"},
+				"response": {"status": 404, "content": {"mimeType": "application/json", "text": "{\"error\": \"not found\"}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/avatar.png"},
+				"response": {"status": 200, "content": {"mimeType": "image/png", "text": "iVBORw0KGgo="}}
+			},
+			{
+				"request": {"method": "post", "url": "https://api.example.com/users"},
+				"response": {"status": 201, "content": {"mimeType": "application/vnd.api+json", "text": "{\"id\": 3}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/users/6f1c2d3e-aaaa-4bbb-8ccc-0123456789ab/posts"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "[{\"title\": \"a\"}, {\"title\": \"b\"}]"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/tags"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "[\"a\", \"b\"]"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/users/count"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "42"}}
+			}
+		]
+	}
+}`)
+
+func TestHAR(t *testing.T) {
+	expected := "package main\n\ntype GetUsersIDPostsResponse []Post\n\ntype GetTagsResponse []string\n\ntype GetUsersCountResponse int\n\ntype GetUsersIDResponse struct {\n\tID    int    `json:\"id\"`\n\tName  string `json:\"name\"`\n\tEmail string `json:\"email\"`\n\tTeam  `json:\"team\"`\n}\n\ntype PostUsersResponse struct {\n\tID int `json:\"id\"`\n}\n\ntype Post struct {\n\tTitle string `json:\"title\"`\n}\n\ntype Team struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n}\n"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("har", bytes.NewReader(harDoc), &buff)
+	calvin.From = HAR
+	calvin.FieldOrder = SourceOrder
+	calvin.Verify = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+func TestHAREndpointWriter(t *testing.T) {
+	expected := map[string]string{
+		"GetUsersIDResponse":      "package main\n\ntype GetUsersIDResponse struct {\n\tID    int    `json:\"id\"`\n\tName  string `json:\"name\"`\n\tEmail string `json:\"email\"`\n\tTeam  `json:\"team\"`\n}\n\ntype Team struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n}\n",
+		"PostUsersResponse":       "package main\n\ntype PostUsersResponse struct {\n\tID int `json:\"id\"`\n}\n",
+		"GetUsersIDPostsResponse": "package main\n\ntype GetUsersIDPostsResponse []Post\n\ntype Post struct {\n\tTitle string `json:\"title\"`\n}\n",
+		"GetTagsResponse":         "package main\n\ntype GetTagsResponse []string\n",
+		"GetUsersCountResponse":   "package main\n\ntype GetUsersCountResponse int\n",
+	}
+	var buff bytes.Buffer
+	files := map[string]*bytes.Buffer{}
+	calvin := NewTransmogrifier("har", bytes.NewReader(harDoc), &buff)
+	calvin.From = HAR
+	calvin.FieldOrder = SourceOrder
+	calvin.SetEndpointWriter(func(name string) (io.Writer, error) {
+		files[name] = &bytes.Buffer{}
+		return files[name], nil
+	})
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.Len() != 0 {
+		t.Errorf("expected nothing to be written to the output, got %q", buff.String())
+	}
+	if len(files) != len(expected) {
+		t.Errorf("got %d endpoint files want %d", len(files), len(expected))
+	}
+	for name, src := range expected {
+		if files[name] == nil {
+			t.Errorf("%s: expected a file", name)
+			continue
+		}
+		if files[name].String() != src {
+			t.Errorf("%s: got %q want %q", name, files[name].String(), src)
+		}
+	}
+}
+
+// TestHAREndpointImports tests that each endpoint's file imports the packages
+// that its types use, and only those.
+func TestHAREndpointImports(t *testing.T) {
+	doc := `{"log": {"version": "1.2", "entries": [
+		{"request": {"method": "GET", "url": "https://api.example.com/status"}, "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"state\": \"up\"}"}}},
+		{"request": {"method": "GET", "url": "https://api.example.com/status"}, "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"state\": \"down\"}"}}},
+		{"request": {"method": "GET", "url": "https://api.example.com/snippet"}, "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"code\": \"fmt.Println(json.Valid(b))\"}"}}}
+	]}}`
+	var buff bytes.Buffer
+	files := map[string]*bytes.Buffer{}
+	calvin := NewTransmogrifier("har", strings.NewReader(doc), &buff)
+	calvin.From = HAR
+	calvin.Comments = true
+	calvin.EnumThreshold = 2
+	calvin.StrictEnums = true
+	calvin.SetEndpointWriter(func(name string) (io.Writer, error) {
+		files[name] = &bytes.Buffer{}
+		return files[name], nil
+	})
+	err := calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	for name, imports := range map[string]string{
+		"GetStatusResponse":  "import (\n\t\"encoding/json\"\n\t\"fmt\"\n)",
+		"GetSnippetResponse": "",
+	} {
+		if files[name] == nil {
+			t.Errorf("%s: expected a file", name)
+			continue
+		}
+		src := files[name].String()
+		if strings.Contains(src, "import") != (imports != "") || !strings.Contains(src, imports) {
+			t.Errorf("%s: expected the imports %q, got %q", name, imports, src)
+		}
+	}
+}
+
+func TestHARErrors(t *testing.T) {
+	tests := []struct {
+		har string
+		err string
+	}{
+		{`{"entries": []}`, "expected an object with a log"},
+		{`{"log": {}}`, "expected the log to have entries"},
+		{`{"log": {"entries": [{"request": {}}]}}`, "entry 0: expected a request and a response"},
+		{`{"log": {"entries": [{"request": {"method": "GET", "url": "/x"}, "response": {"status": 500, "content": {"mimeType": "application/json", "text": "{}"}}}]}}`, "doesn't have any successful responses"},
+	}
+	for i, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("har", strings.NewReader(test.har), &buff)
+		calvin.From = HAR
+		err := calvin.Gen()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%d: got error %v, want %q", i, err, test.err)
+		}
+	}
+}
+
+func TestHARPath(t *testing.T) {
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{"https://example.com/users/42", "/users/{id}"},
+		{"https://example.com/users/42/", "/users/{id}"},
+		{"https://example.com/v1/orders/6f1c2d3e-aaaa-4bbb-8ccc-0123456789ab/items?page=2", "/v1/orders/{id}/items"},
+		{"https://example.com/commits/9fceb02d0ae598e95dc970b74767f19372d61af8", "/commits/{id}"},
+		{"https://example.com/feed/deadbeef", "/feed/deadbeef"},
+		{"https://example.com", "/"},
+	}
+	for _, test := range tests {
+		p, err := harPath(test.url)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.url, err)
+			continue
+		}
+		if p != test.expected {
+			t.Errorf("%s: got %q want %q", test.url, p, test.expected)
+		}
+	}
+}
+
+func TestHARVerify(t *testing.T) {
+	har := `{"log": {"entries": [
+		{"request": {"method": "GET", "url": "/things"}, "response": {"status": 200, "content": {"mimeType": "application/json", "text": "[{\"id\": 1}]"}}},
+		{"request": {"method": "GET", "url": "/things"}, "response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\": 2}"}}}
+	]}}`
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("har", strings.NewReader(har), &buff)
+	calvin.From = HAR
+	calvin.Verify = true
+	err := calvin.Gen()
+	verr, ok := err.(VerifyError)
+	if !ok {
+		t.Fatalf("expected a VerifyError, got %v", err)
+	}
+	expected := []string{"GET /things: response 2: $: cannot decode object into []Thing"}
+	if !reflect.DeepEqual(verr.Problems, expected) {
+		t.Errorf("got %q want %q", verr.Problems, expected)
+	}
+}
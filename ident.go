@@ -0,0 +1,99 @@
+package json2go
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// identifier returns s as a valid, exported, Go identifier.  Runes that
+// aren't letters, digits, or underscores are discarded.  If nothing is left,
+// fallback is used.  A leading digit is converted to its word equivalent and
+// if the first rune isn't an uppercase letter, e.g. it is an underscore or a
+// letter without case, the identifier is prefixed with X.
+func identifier(s, fallback string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, s)
+	if s == "" {
+		return fallback
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	if w := numToAlpha(r); w != "" {
+		s = w + s[n:]
+		r, _ = utf8.DecodeRuneInString(s)
+	}
+	if unicode.IsLower(r) {
+		return upperFirst(s)
+	}
+	if !unicode.IsUpper(r) {
+		return "X" + s
+	}
+	return s
+}
+
+// pkgName returns s as a valid package name: s is lowercased and any runes
+// that can't be part of an identifier, e.g. the dash in my-service, are
+// discarded.  An error is returned if the result isn't a valid identifier,
+// or if it is a Go keyword or a predeclared identifier, e.g. type or string.
+func pkgName(s string) (string, error) {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, s)
+	switch {
+	case !token.IsIdentifier(name):
+		return "", fmt.Errorf("invalid package name %q: not a valid Go identifier", s)
+	case token.IsKeyword(name):
+		return "", fmt.Errorf("invalid package name %q: %s is a Go keyword", s, name)
+	case types.Universe.Lookup(name) != nil:
+		return "", fmt.Errorf("invalid package name %q: %s is a predeclared Go identifier", s, name)
+	}
+	return name, nil
+}
+
+// uniqueName returns name if it isn't in used, otherwise name with the
+// lowest numeric suffix, starting at 2, that isn't in used.  The returned
+// name is added to used.
+func uniqueName(name string, used map[string]struct{}) string {
+	n := name
+	for i := 2; ; i++ {
+		if _, ok := used[n]; !ok {
+			break
+		}
+		n = fmt.Sprintf("%s%d", name, i)
+	}
+	used[n] = struct{}{}
+	return n
+}
+
+// fieldNames returns the field names for the fields, in the same order.  The
+// names are valid, exported, identifiers and are unique within the struct:
+// if more than one key results in the same name, e.g. foo_bar and fooBar,
+// the first one, in field order, gets the name and the others get a numeric
+// suffix.
+func (t *Transmogrifier) fieldNames(fields []*field) []string {
+	names := make([]string, len(fields))
+	used := make(map[string]struct{}, len(fields))
+	for i, f := range fields {
+		names[i] = uniqueName(identifier(t.getNamer().FieldName(f.key), "Field"), used)
+	}
+	return names
+}
+
+// typeName returns a valid, exported, identifier for the type name that is
+// unique within the generated code.
+func (t *Transmogrifier) typeName(name string) string {
+	if t.typeNames == nil {
+		t.typeNames = map[string]struct{}{}
+	}
+	return uniqueName(identifier(name, "Type"), t.typeNames)
+}
@@ -0,0 +1,88 @@
+package json2go
+
+import "testing"
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Foo", "Foo"},
+		{"foo", "Foo"},
+		{"Foo-bar", "Foobar"},
+		{"Foo bar", "Foobar"},
+		{"Foo/Bar[0]", "FooBar0"},
+		{`"Foo"`, "Foo"},
+		{"🚀", "Field"},
+		{"", "Field"},
+		{"_foo", "X_foo"},
+		{"日本語a", "X日本語a"},
+		{"бsdf", "Бsdf"},
+		{"1st", "Onest"},
+	}
+	for i, test := range tests {
+		s := identifier(test.name, "Field")
+		if s != test.expected {
+			t.Errorf("%d: identifier(%q): got %q, want %q", i, test.name, s, test.expected)
+		}
+	}
+}
+
+func TestUniqueName(t *testing.T) {
+	used := map[string]struct{}{}
+	for i, expected := range []string{"Foo", "Foo2", "Foo3"} {
+		if s := uniqueName("Foo", used); s != expected {
+			t.Errorf("%d: got %q, want %q", i, s, expected)
+		}
+	}
+	// a name that is the same as a suffixed one
+	if s := uniqueName("Foo2", used); s != "Foo22" {
+		t.Errorf("got %q, want %q", s, "Foo22")
+	}
+}
+
+func TestPkgName(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		err      bool
+	}{
+		{"main", "main", false},
+		{"Foo", "foo", false},
+		{"my-service", "myservice", false},
+		{"my.service", "myservice", false},
+		{"foo_bar", "foo_bar", false},
+		{"type", "", true},
+		{"func", "", true},
+		{"string", "", true},
+		{"nil", "", true},
+		{"2fast", "", true},
+		{"---", "", true},
+	}
+	for i, test := range tests {
+		name, err := pkgName(test.name)
+		if (err != nil) != test.err {
+			t.Errorf("%d: pkgName(%q): got error %v, want error %t", i, test.name, err, test.err)
+			continue
+		}
+		if name != test.expected {
+			t.Errorf("%d: pkgName(%q): got %q, want %q", i, test.name, name, test.expected)
+		}
+	}
+}
+
+func TestSetPkg(t *testing.T) {
+	calvin := NewTransmogrifier("test", nil, nil)
+	if err := calvin.SetPkg("my-service"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if calvin.pkg != "myservice" {
+		t.Errorf("got %q, want %q", calvin.pkg, "myservice")
+	}
+	if err := calvin.SetPkg("type"); err == nil {
+		t.Error("expected an error, got none")
+	}
+	if calvin.pkg != "myservice" {
+		t.Errorf("got %q, want %q", calvin.pkg, "myservice")
+	}
+}
@@ -0,0 +1,501 @@
+package json2go
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// kind is the kind of JSON value that was observed.
+type kind int
+
+const (
+	// nullKind is used when only nulls, or nothing, has been observed.  The
+	// type of a null cannot be determined.
+	nullKind kind = iota
+	boolKind
+	intKind
+	floatKind
+	stringKind
+	objectKind
+	arrayKind
+	// mixedKind is used when values of incompatible kinds were observed.
+	mixedKind
+)
+
+func (k kind) String() string {
+	switch k {
+	case nullKind:
+		return "null"
+	case boolKind:
+		return "bool"
+	case intKind:
+		return "int"
+	case floatKind:
+		return "float"
+	case stringKind:
+		return "string"
+	case objectKind:
+		return "object"
+	case arrayKind:
+		return "array"
+	}
+	return "mixed"
+}
+
+// jsonType is the type inferred from all of the values observed at a given
+// location within the JSON.  Every value added to a jsonType is treated as
+// a sample of the same thing.
+type jsonType struct {
+	kind kind
+	// name is the name of the Go type an object is defined as, once its
+	// definition has been generated.
+	name string
+	// fields holds the fields of an object, by key.
+	fields map[string]*field
+	// order is the order of the object's keys in the source, if known.
+	order []string
+	// objects is the number of objects that have been observed.
+	objects int
+	// elem is the type of an array's elements.
+	elem *jsonType
+	// strs is the number of strings that have been observed.  The not*
+	// flags are set once a string that can't be decoded as that type has
+	// been observed.
+	strs     int
+	notInt   bool
+	notFloat bool
+	notBool  bool
+	// notTime is set once a string that isn't an RFC 3339 timestamp has
+	// been observed.
+	notTime bool
+	// strVals holds the distinct strings that have been observed.  Once
+	// there are more than maxEnumValues of them, they are no longer
+	// tracked and manyStrs is set.
+	strVals  map[string]struct{}
+	manyStrs bool
+	// nulls is the number of nulls that have been observed.
+	nulls int
+	// nums is the number of numbers that have been observed and min and
+	// max are the smallest and largest of them.
+	nums     int
+	min, max float64
+	// lens is the number of strings and arrays that have been observed
+	// and minLen and maxLen are the shortest and longest lengths of them.
+	lens           int
+	minLen, maxLen int
+	// example is the first bool, number, or string that was observed.
+	example interface{}
+	// mapOf is the type of the values of an object that is a map, rather
+	// than a struct, e.g. a schema's additionalProperties.
+	mapOf *jsonType
+	// declared is true if the type was declared, e.g. by a schema,
+	// rather than inferred from samples.  A declared string type with
+	// strVals is an enum.
+	declared bool
+	// desc is the declared description of the type.
+	desc string
+	// format is the declared format of a string, e.g. date-time.
+	format string
+	// defName is the name of the definition the type was declared by; if
+	// set, it is used to name the type.
+	defName string
+}
+
+// maxEnumValues is the maximum number of distinct strings that are tracked
+// per type for enum detection.
+const maxEnumValues = 64
+
+// field is a key within an object.
+type field struct {
+	key string
+	typ *jsonType
+	// count is the number of objects in which the key was present.
+	count int
+}
+
+// inferType returns the type inferred from the values; each value is
+// treated as a sample of the same type.  The orders are the key orders of
+// the values, if known; orders may be nil.
+func inferType(vals []interface{}, orders []*keyOrder) *jsonType {
+	var t jsonType
+	for i, v := range vals {
+		var o *keyOrder
+		if i < len(orders) {
+			o = orders[i]
+		}
+		t.add(v, o)
+	}
+	return &t
+}
+
+// add adds the value, as decoded by encoding/json, to the type.  The order
+// is the key order of the value, if known.
+func (t *jsonType) add(v interface{}, o *keyOrder) {
+	switch v := v.(type) {
+	case nil:
+		t.nulls++
+		return
+	case bool:
+		t.setKind(boolKind)
+		t.addExample(v)
+	case float64:
+		t.addNum(v)
+		t.addExample(v)
+		// a number that was written as a float, e.g. 72.0, is a float:
+		// encoding/json can't decode it into an int
+		if v == float64(int64(v)) && !o.isFloat() {
+			t.setKind(intKind)
+			return
+		}
+		t.setKind(floatKind)
+	case string:
+		t.setKind(stringKind)
+		t.addLen(utf8.RuneCountInString(v))
+		t.addString(v)
+		t.addExample(v)
+	case map[string]interface{}:
+		t.setKind(objectKind)
+		if t.kind != objectKind {
+			return
+		}
+		if t.fields == nil {
+			t.fields = make(map[string]*field, len(v))
+		}
+		t.objects++
+		if o != nil {
+			t.addKeys(o.keys)
+		}
+		for k, val := range v {
+			f, ok := t.fields[k]
+			if !ok {
+				f = &field{key: k, typ: &jsonType{}}
+				t.fields[k] = f
+			}
+			f.count++
+			f.typ.add(val, o.child(k))
+		}
+	case []interface{}:
+		t.setKind(arrayKind)
+		if t.kind != arrayKind {
+			return
+		}
+		if t.elem == nil {
+			t.elem = &jsonType{}
+		}
+		t.addLen(len(v))
+		for i, e := range v {
+			t.elem.add(e, o.elem(i))
+		}
+	}
+}
+
+// addNum adds v to the observed numeric range.
+func (t *jsonType) addNum(v float64) {
+	if t.nums == 0 || v < t.min {
+		t.min = v
+	}
+	if t.nums == 0 || v > t.max {
+		t.max = v
+	}
+	t.nums++
+}
+
+// addExample sets the example value, unless there already is one.
+func (t *jsonType) addExample(v interface{}) {
+	if t.example == nil {
+		t.example = v
+	}
+}
+
+// addLen adds n to the observed range of lengths.
+func (t *jsonType) addLen(n int) {
+	if t.lens == 0 || n < t.minLen {
+		t.minLen = n
+	}
+	if t.lens == 0 || n > t.maxLen {
+		t.maxLen = n
+	}
+	t.lens++
+}
+
+// merge adds everything observed by o to t.
+func (t *jsonType) merge(o *jsonType) {
+	if o == nil || o == t {
+		return
+	}
+	if o.kind != nullKind {
+		t.setKind(o.kind)
+	}
+	t.nulls += o.nulls
+	if o.example != nil {
+		t.addExample(o.example)
+	}
+	if o.nums > 0 {
+		if t.nums == 0 || o.min < t.min {
+			t.min = o.min
+		}
+		if t.nums == 0 || o.max > t.max {
+			t.max = o.max
+		}
+		t.nums += o.nums
+	}
+	if o.lens > 0 {
+		if t.lens == 0 || o.minLen < t.minLen {
+			t.minLen = o.minLen
+		}
+		if t.lens == 0 || o.maxLen > t.maxLen {
+			t.maxLen = o.maxLen
+		}
+		t.lens += o.lens
+	}
+	switch t.kind {
+	case objectKind:
+		if o.kind != objectKind {
+			return
+		}
+		if t.fields == nil {
+			t.fields = make(map[string]*field, len(o.fields))
+		}
+		t.objects += o.objects
+		t.addKeys(o.order)
+		for k, of := range o.fields {
+			f, ok := t.fields[k]
+			if !ok {
+				t.fields[k] = of
+				continue
+			}
+			f.count += of.count
+			f.typ.merge(of.typ)
+		}
+	case arrayKind:
+		if o.kind != arrayKind || o.elem == nil {
+			return
+		}
+		if t.elem == nil {
+			t.elem = &jsonType{}
+		}
+		t.elem.merge(o.elem)
+	case stringKind:
+		t.strs += o.strs
+		t.notInt = t.notInt || o.notInt
+		t.notFloat = t.notFloat || o.notFloat
+		t.notBool = t.notBool || o.notBool
+		t.notTime = t.notTime || o.notTime
+		if t.manyStrs || o.manyStrs {
+			t.strVals = nil
+			t.manyStrs = true
+			return
+		}
+		for s := range o.strVals {
+			if t.strVals == nil {
+				t.strVals = map[string]struct{}{}
+			}
+			t.strVals[s] = struct{}{}
+		}
+		if len(t.strVals) > maxEnumValues {
+			t.strVals = nil
+			t.manyStrs = true
+		}
+	}
+}
+
+// setKind reconciles k with the kind already observed.  Ints are widened to
+// floats; any other conflict results in mixedKind.
+func (t *jsonType) setKind(k kind) {
+	switch {
+	case t.kind == nullKind || t.kind == k:
+		t.kind = k
+	case t.kind == intKind && k == floatKind, t.kind == floatKind && k == intKind:
+		t.kind = floatKind
+	default:
+		t.kind = mixedKind
+	}
+}
+
+// addString records s as a distinct value and whether it could be the string
+// encoding of a number or a bool.
+func (t *jsonType) addString(s string) {
+	t.strs++
+	if !t.manyStrs {
+		if t.strVals == nil {
+			t.strVals = map[string]struct{}{}
+		}
+		t.strVals[s] = struct{}{}
+		if len(t.strVals) > maxEnumValues {
+			t.strVals = nil
+			t.manyStrs = true
+		}
+	}
+	if !t.notFloat {
+		// encoding/json doesn't allow space around a string-encoded
+		// number, which json.Unmarshal does
+		var v interface{}
+		if strings.TrimSpace(s) != s {
+			t.notFloat = true
+		} else if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.notFloat = true
+		} else if _, ok := v.(float64); !ok {
+			t.notFloat = true
+		}
+	}
+	if t.notFloat {
+		t.notInt = true
+	} else if !t.notInt {
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			t.notInt = true
+		}
+	}
+	if s != "true" && s != "false" {
+		t.notBool = true
+	}
+	if !t.notTime {
+		if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+			t.notTime = true
+		}
+	}
+}
+
+// stringEncoded returns the Go type of the values that every observed string
+// encodes, if any.  An empty string is returned if the strings are just
+// strings.
+func (t *jsonType) stringEncoded() string {
+	if t.kind != stringKind || t.strs == 0 {
+		return ""
+	}
+	switch {
+	case !t.notInt:
+		return "int64"
+	case !t.notFloat:
+		return "float64"
+	case !t.notBool:
+		return "bool"
+	}
+	return ""
+}
+
+// isDateTime returns whether the type is a string that was either declared
+// with the date-time format or every observed value of which is an RFC 3339
+// timestamp.
+func (t *jsonType) isDateTime() bool {
+	if t.kind != stringKind {
+		return false
+	}
+	if t.declared {
+		return t.format == "date-time"
+	}
+	return t.strs > 0 && !t.notTime
+}
+
+// typeTOML types the type, and those within it, by TOML's rules rather than
+// JSON's: TOML has datetimes, so a string that is always an offset datetime,
+// which is an RFC 3339 timestamp, is a date-time.  It must be called before
+// foldRecursive.
+func (t *jsonType) typeTOML() {
+	switch t.kind {
+	case stringKind:
+		if t.format == "" && t.isDateTime() {
+			t.format = "date-time"
+		}
+	case objectKind:
+		for _, f := range t.fields {
+			f.typ.typeTOML()
+		}
+		if t.mapOf != nil {
+			t.mapOf.typeTOML()
+		}
+	case arrayKind:
+		if t.elem != nil {
+			t.elem.typeTOML()
+		}
+	}
+}
+
+// enumValues returns the sorted, distinct, strings observed if the type is a
+// string with at most max distinct values observed across more than one
+// sample.  Otherwise nil is returned.
+//
+// Declared types are enums if any values were declared, regardless of max.
+func (t *jsonType) enumValues(max int) []string {
+	if t.kind != stringKind || t.manyStrs || len(t.strVals) == 0 || t.format == "date-time" {
+		return nil
+	}
+	if !t.declared && (t.strs < 2 || len(t.strVals) > max) {
+		return nil
+	}
+	vals := make([]string, 0, len(t.strVals))
+	for v := range t.strVals {
+		vals = append(vals, v)
+	}
+	sort.Strings(vals)
+	return vals
+}
+
+// sortedFields returns the object's fields sorted by key.
+func (t *jsonType) sortedFields() []*field {
+	fields := make([]*field, 0, len(t.fields))
+	for _, f := range t.fields {
+		fields = append(fields, f)
+	}
+	sort.Sort(byKey(fields))
+	return fields
+}
+
+// byKey sorts fields by their JSON key.
+type byKey []*field
+
+func (f byKey) Len() int           { return len(f) }
+func (f byKey) Swap(i, j int)      { f[i], f[j] = f[j], f[i] }
+func (f byKey) Less(i, j int) bool { return f[i].key < f[j].key }
+
+// goType returns the Go type for t.  Objects are represented by name.
+func (t *jsonType) goType(name string) string {
+	switch t.kind {
+	case boolKind:
+		return "bool"
+	case intKind:
+		return "int"
+	case floatKind:
+		return "float64"
+	case stringKind:
+		if t.format == "date-time" {
+			return "time.Time"
+		}
+		return "string"
+	case objectKind:
+		if t.mapOf != nil {
+			return "map[string]" + t.mapOf.goType(name)
+		}
+		return name
+	case arrayKind:
+		if t.elem == nil {
+			return "[]interface{}"
+		}
+		return "[]" + t.elem.goType(name)
+	}
+	return "interface{}"
+}
+
+// object returns the object type that t is composed of, if any: either t
+// itself or the element, or value, type of a, possibly nested, array or map
+// of objects.
+func (t *jsonType) object() *jsonType {
+	for t != nil {
+		switch t.kind {
+		case objectKind:
+			if t.mapOf != nil {
+				t = t.mapOf
+				continue
+			}
+			return t
+		case arrayKind:
+			t = t.elem
+			continue
+		}
+		return nil
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package json2go
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// irregulars are plurals, lowercased, that aren't handled by the
+// singularization rules and their singular form.
+var irregulars = map[string]string{
+	"aliases":     "alias",
+	"analyses":    "analysis",
+	"atlases":     "atlas",
+	"avalanches":  "avalanche",
+	"axes":        "axis",
+	"biases":      "bias",
+	"bikinis":     "bikini",
+	"bonuses":     "bonus",
+	"buses":       "bus",
+	"campuses":    "campus",
+	"canvases":    "canvas",
+	"children":    "child",
+	"cookies":     "cookie",
+	"criteria":    "criterion",
+	"emojis":      "emoji",
+	"emus":        "emu",
+	"feet":        "foot",
+	"gases":       "gas",
+	"geese":       "goose",
+	"gurus":       "guru",
+	"halves":      "half",
+	"heroes":      "hero",
+	"indices":     "index",
+	"kiwis":       "kiwi",
+	"knives":      "knife",
+	"leaves":      "leaf",
+	"lives":       "life",
+	"matrices":    "matrix",
+	"men":         "man",
+	"menus":       "menu",
+	"mice":        "mouse",
+	"movies":      "movie",
+	"oxen":        "ox",
+	"people":      "person",
+	"phenomena":   "phenomenon",
+	"potatoes":    "potato",
+	"quizzes":     "quiz",
+	"safaris":     "safari",
+	"shelves":     "shelf",
+	"skis":        "ski",
+	"statuses":    "status",
+	"taxis":       "taxi",
+	"teeth":       "tooth",
+	"tutus":       "tutu",
+	"vertices":    "vertex",
+	"viruses":     "virus",
+	"wikis":       "wiki",
+	"wives":       "wife",
+	"wolves":      "wolf",
+	"women":       "woman",
+	"data":        "data",
+	"equipment":   "equipment",
+	"information": "information",
+	"metadata":    "metadata",
+	"news":        "news",
+	"series":      "series",
+	"species":     "species",
+}
+
+// inflector singularizes English words.
+type inflector struct {
+	// irregulars are lowercased plurals and their singular form; it
+	// starts as a copy of the package's irregulars.
+	irregulars map[string]string
+	// initialisms are the initialisms whose plurals are singularized by
+	// dropping the s, e.g. APIs; if nil, the common initialisms are used.
+	initialisms Initialisms
+}
+
+// chStems are the endings of words that end in ch, and whose plural ends in
+// ches; the plurals of other words ending in ches, e.g. caches, just drop the
+// s.
+var chStems = []string{"tch", "rch", "nch", "lch", "each", "oach", "eech", "ouch", "wich", "rich", "ttach", "etach"}
+
+func newInflector() *inflector {
+	inf := &inflector{irregulars: make(map[string]string, len(irregulars))}
+	for k, v := range irregulars {
+		inf.irregulars[k] = v
+	}
+	return inf
+}
+
+// addIrregular adds an irregular plural, and its singular form, to the
+// inflector.  Uncountable words can be added by using the same word for
+// both.
+func (inf *inflector) addIrregular(singular, plural string) {
+	inf.irregulars[strings.ToLower(plural)] = strings.ToLower(singular)
+}
+
+// singularize returns the singular form of the last word in s: a word is a
+// run of letters which starts either after a rune that isn't a letter or at
+// an uppercase letter that follows a lowercase letter, e.g. the last word of
+// both sub_categories and subCategories is categories.  The case of the
+// word is preserved.
+func (inf *inflector) singularize(s string) string {
+	i := lastWordIndex(s)
+	prefix, word := s[:i], s[i:]
+	if len(word) == 0 {
+		return s
+	}
+	lower := strings.ToLower(word)
+	singular, ok := inf.irregulars[lower]
+	if !ok {
+		singular, ok = inf.initialism(word)
+	}
+	if !ok {
+		singular = singularRule(lower)
+	}
+	switch {
+	case len(lower) == len(word) && strings.HasPrefix(lower, singular):
+		singular = word[:len(singular)]
+	case word == strings.ToUpper(word) && len(word) > 1:
+		singular = strings.ToUpper(singular)
+	case word != lower:
+		r, n := utf8.DecodeRuneInString(singular)
+		singular = string(unicode.ToUpper(r)) + singular[n:]
+	}
+	return prefix + singular
+}
+
+// initialism returns the singular form, lowercased, of a word that is an
+// initialism, which is its own singular, or the plural of one, e.g. APIs,
+// apis, or IDs, which drops the s; otherwise false is returned.  A word that
+// is uppercase, but for a trailing s, is the plural of an initialism even if
+// it isn't one of the inflector's.
+func (inf *inflector) initialism(word string) (string, bool) {
+	in := inf.initialisms
+	if in == nil {
+		in = commonInitialisms
+	}
+	upper := strings.ToUpper(word)
+	if _, ok := in[upper]; ok {
+		return strings.ToLower(word), true
+	}
+	if len(word) < 3 || word[len(word)-1] != 's' {
+		return "", false
+	}
+	stem := word[:len(word)-1]
+	if _, ok := in[upper[:len(upper)-1]]; ok || stem == strings.ToUpper(stem) {
+		return strings.ToLower(stem), true
+	}
+	return "", false
+}
+
+// singularRule returns the singular form of a lowercased word using the
+// regular English pluralization rules.
+func singularRule(s string) string {
+	switch {
+	case strings.HasSuffix(s, "ies") && len(s) > 4:
+		return s[:len(s)-3] + "y"
+	case strings.HasSuffix(s, "sses"), strings.HasSuffix(s, "xes"),
+		strings.HasSuffix(s, "shes"), strings.HasSuffix(s, "zzes"):
+		return s[:len(s)-2]
+	case strings.HasSuffix(s, "ches"):
+		for _, stem := range chStems {
+			if strings.HasSuffix(s[:len(s)-2], stem) {
+				return s[:len(s)-2]
+			}
+		}
+		return s[:len(s)-1]
+	case strings.HasSuffix(s, "ss"), strings.HasSuffix(s, "us"),
+		strings.HasSuffix(s, "is"):
+		return s
+	case strings.HasSuffix(s, "s") && len(s) > 1:
+		return s[:len(s)-1]
+	}
+	return s
+}
+
+// lastWordIndex returns the index of the start of the last word in s.
+func lastWordIndex(s string) int {
+	i := len(s)
+	for i > 0 {
+		r, n := utf8.DecodeLastRuneInString(s[:i])
+		if !unicode.IsLetter(r) {
+			break
+		}
+		if unicode.IsUpper(r) {
+			// the start of a camelCase word, unless it is within a
+			// run of uppercase letters, e.g. IDs or URLS
+			p, _ := utf8.DecodeLastRuneInString(s[:i-n])
+			if !unicode.IsUpper(p) {
+				return i - n
+			}
+		}
+		i -= n
+	}
+	return i
+}
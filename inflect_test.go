@@ -0,0 +1,76 @@
+package json2go
+
+import "testing"
+
+func TestSingularizeWord(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"items", "item"},
+		{"Items", "Item"},
+		{"categories", "category"},
+		{"people", "person"},
+		{"People", "Person"},
+		{"boxes", "box"},
+		{"addresses", "address"},
+		{"matches", "match"},
+		{"watches", "watch"},
+		{"branches", "branch"},
+		{"searches", "search"},
+		{"caches", "cache"},
+		{"niches", "niche"},
+		{"avalanches", "avalanche"},
+		{"headaches", "headache"},
+		{"gases", "gas"},
+		{"cases", "case"},
+		{"databases", "database"},
+		{"apis", "api"},
+		{"APIs", "API"},
+		{"api_keys", "api_key"},
+		{"userAPIs", "userAPI"},
+		{"dns", "dns"},
+		{"basis", "basis"},
+		{"STATUS", "STATUS"},
+		{"status", "status"},
+		{"statuses", "status"},
+		{"analysis", "analysis"},
+		{"menus", "menu"},
+		{"Menus", "Menu"},
+		{"kiwis", "kiwi"},
+		{"sub_menus", "sub_menu"},
+		{"taxis", "taxi"},
+		{"data", "data"},
+		{"sub_categories", "sub_category"},
+		{"subCategories", "subCategory"},
+		{"user_ids", "user_id"},
+		{"userIDs", "userID"},
+		{"URLS", "URL"},
+		{"foo", "foo"},
+		{"s", "s"},
+		{"", ""},
+		{"v2", "v2"},
+	}
+	inf := newInflector()
+	for i, test := range tests {
+		s := inf.singularize(test.word)
+		if s != test.expected {
+			t.Errorf("%d: singularize(%q): got %q, want %q", i, test.word, s, test.expected)
+		}
+	}
+}
+
+func TestAddIrregular(t *testing.T) {
+	inf := newInflector()
+	if s := inf.singularize("octopi"); s != "octopi" {
+		t.Errorf("got %q, want %q", s, "octopi")
+	}
+	inf.addIrregular("octopus", "Octopi")
+	if s := inf.singularize("octopi"); s != "octopus" {
+		t.Errorf("got %q, want %q", s, "octopus")
+	}
+	// the package's irregulars aren't affected
+	if _, ok := irregulars["octopi"]; ok {
+		t.Error("expected the package irregulars to be unchanged")
+	}
+}
@@ -4,12 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"go/format"
 	"io"
-	"reflect"
 	"sort"
 	"strings"
-	"sync"
+	"text/template"
 	"unicode"
 	"unicode/utf8"
 
@@ -26,26 +24,25 @@ func (e ShortWriteError) Error() string {
 	return fmt.Sprintf("%s: short write: wrote %d bytes of %d", e.operation, e.n, e.written)
 }
 
-// stringValues is a slice of reflect.Value holding *reflect.StringValue.
-// It implements the methods to sort by string.
-type stringValues []reflect.Value
-
-func (sv stringValues) Len() int           { return len(sv) }
-func (sv stringValues) Swap(i, j int)      { sv[i], sv[j] = sv[j], sv[i] }
-func (sv stringValues) Less(i, j int) bool { return sv.get(i) < sv.get(j) }
-func (sv stringValues) get(i int) string   { return sv[i].String() }
-
 // Transmogrifier turns JSON into Go struct definitions.
 type Transmogrifier struct {
-	r          io.Reader
-	w          io.Writer
-	jw         io.Writer
-	name       string
-	structName string
-	pkg        string
+	r  io.Reader
+	w  io.Writer
+	jw io.Writer
+	// tw is the writer to which the round-trip test is written.
+	tw io.Writer
+	// ew returns the writer to which the types of a HAR endpoint are
+	// written; if nil, they are written to w.
+	ew func(name string) (io.Writer, error)
+	// testJSONFile is the file the round-trip test reads the source JSON
+	// from; if empty, the JSON is embedded in the test.
+	testJSONFile string
+	name         string
+	structName   string
+	pkg          string
 	// tagKeys are additional tag keys that should be included in the
 	// field's tag.  These tags are in addition to the `json` tag.
-	tagKeys []string
+	tagKeys []TagKey
 	// ImportJSON is used to control whether or not an import statement
 	// for encoding/json should be generated.
 	ImportJSON bool
@@ -54,6 +51,50 @@ type Transmogrifier struct {
 	// with '\t', tab, as the indent.  This only applies when the output
 	// destination is not stdout.
 	WriteJSON bool
+	// Format is the format of the output: Go, the default, or a JSON
+	// Schema that describes the type.  Templates, and formatting, only
+	// apply to Go.
+	Format OutputFormat
+	// InputFormat is the encoding of the input: JSON, the default, YAML,
+	// TOML, or lenient JSON, JSON5.  Input that isn't JSON is converted to
+	// JSON, with the order of its keys preserved, before the types are
+	// generated; the JSON is what WriteJSON writes.  For YAML and TOML, a
+	// tag key for the format, yaml or toml, is added to each field's tag,
+	// unless one has been set.
+	InputFormat InputFormat
+	// Compression is the compression of the input: by default, it is
+	// detected from the input's magic bytes, see DetectCompression.
+	// Compressed input is decompressed before it is decoded; if it is
+	// JSON, it is indented with tabs, which is what WriteJSON writes.
+	Compression Compression
+	// From is what the input JSON is: samples of the type, the default, or
+	// a JSON Schema that declares it.
+	From Input
+	// Operations is used to control whether or not, when the input is an
+	// OpenAPI document, types are generated for the request and response
+	// bodies of each operation, named <OperationID>Request and
+	// <OperationID>Response.  Bodies whose schema is a component are
+	// aliases of the component's type, e.g. type CreatePetResponse = Pet,
+	// and array bodies are slices, e.g. type ListPetsResponse []Pet.
+	Operations bool
+	// path is the JSON Pointer, as reference tokens, of the node within
+	// the input that the types are generated from; if nil, it is the
+	// whole input.
+	path []string
+	// Pages is used to control whether or not pagination envelopes are
+	// detected.  If more than one type is generated, e.g. for the
+	// endpoints of a HAR file, the types that have the same keys, one of
+	// which is an array of objects, the items, are pages.  A generic
+	// Page[T] type is defined for the envelope, once, and each page is
+	// an alias of it, e.g. type ListUsersResponse = Page[User].  The shape
+	// of the envelope can be set with SetPageKeys.  The output must be Go.
+	Pages bool
+	// pageKeys are the keys of the pagination envelope, the first being
+	// the key of the items; if empty, the envelope is learned.
+	pageKeys []string
+	// components are the names of the schemas in an OpenAPI document's
+	// components that types are generated for; if empty, all of them.
+	components []string
 	// MapType is used for JSON data that is map[string]interface{},
 	// map[string][]interface{}, or a slice of either of the two. If
 	// true, instead of generating a struct definition for the type, the
@@ -63,12 +104,71 @@ type Transmogrifier struct {
 	//
 	// If false, a struct definition will be generated for the type.
 	MapType bool
+	// GenTest is used to control whether or not a test, that decodes the
+	// source JSON into the generated type, with unknown fields disallowed,
+	// and checks that encoding it results in the same JSON, is generated.
+	// The test is written to the writer set with SetTestWriter.
+	GenTest bool
+	// StringEncoded is used to control whether or not fields whose values
+	// are strings that encode numbers or bools should be typed as what the
+	// strings encode.  If every observed value of a field can be decoded
+	// as an int64, float64, or bool, the field will be of that type and
+	// its json tag will have the `string` option, e.g. `json:"id,string"`.
+	StringEncoded bool
+	// EnumThreshold is the maximum number of distinct values a string
+	// field may have, across multiple samples, for it to be treated as
+	// an enum.  Enums are defined as a named string type with a const
+	// for each observed value and an IsValid method.  If EnumThreshold
+	// is 0, enums will not be detected.
+	EnumThreshold int
+	// StrictEnums is used to control whether or not an UnmarshalJSON
+	// method, that rejects values other than the observed values, is
+	// generated for each enum type.
+	StrictEnums bool
+	// namer creates the field and type names.
+	namer Namer
+	// initialisms are the initialisms used by the built-in Namers; if
+	// nil, the common initialisms are used.
+	initialisms Initialisms
+	// typeNames are the names of the types that have been defined.
+	typeNames map[string]struct{}
+	// inflect is used to singularize the names of the element types of
+	// arrays.
+	inflect *inflector
+	// Verify is used to control whether or not the generated code is
+	// type-checked and every sample in the source JSON is checked to
+	// decode into the generated types before the code is written.  If
+	// either fails, a VerifyError is returned.  The output must be Go.
+	Verify bool
+	// Comments adds a comment to each field with an example value, the JSON
+	// path of the key, how often the key was present, and the observed
+	// range of numbers or lengths.
+	Comments bool
+	// FieldOrder is the order of the fields within each struct definition;
+	// the default is Alphabetical.
+	FieldOrder FieldOrder
+	// TypeOrder is the order of the type declarations; the default is
+	// BreadthFirst.
+	TypeOrder TypeOrder
+	// SkipFormat is used to control whether or not the output is formatted
+	// with gofmt.  This is useful when a template is used to generate
+	// something other than Go.
+	SkipFormat bool
+	// template is used to generate the output; if nil, defaultTemplate is
+	// used.
+	template *template.Template
+	// err is the first error that occurred while defining the structs.
+	err error
+	// imports are the packages that the generated code needs to import.
+	imports map[string]struct{}
 }
 
 // NewTransmogrifier returns a new transmogrifier that reads from r and writes
 // to w.  The name is the name of the type that will be defined from the JSON.
 // Embedded struct names, if there are any embedded structs, are derived from
-// their associated key value.
+// their associated key value.  All type names are exported, so they can't be
+// Go keywords or shadow predeclared identifiers; runes that can't be part of
+// an identifier are discarded when the type is defined.
 func NewTransmogrifier(name string, r io.Reader, w io.Writer) *Transmogrifier {
 	if len(name) == 0 {
 		name = "Type"
@@ -90,13 +190,22 @@ func (t *Transmogrifier) SetStructName(s string) {
 	t.structName = strings.Title(s)
 }
 
-// SetPkg set's the package name to s.  The package name will be lowercased.
-func (t *Transmogrifier) SetPkg(s string) {
+// SetPkg set's the package name to s.  The package name will be lowercased
+// and any runes that can't be part of an identifier, e.g. the dash in
+// my-service, are removed.  If the result is not a valid identifier, or is a
+// Go keyword or predeclared identifier, an error is returned and the package
+// name is left unchanged.
+func (t *Transmogrifier) SetPkg(s string) error {
 	// if empty, do nothing
 	if len(s) == 0 {
-		return
+		return nil
+	}
+	pkg, err := pkgName(s)
+	if err != nil {
+		return err
 	}
-	t.pkg = strings.ToLower(s)
+	t.pkg = pkg
+	return nil
 }
 
 // SetJSONWriter set's the writer to which the original json is written to,
@@ -107,95 +216,252 @@ func (t *Transmogrifier) SetJSONWriter(w io.Writer) {
 
 // SetTagKeys set's the additional keys that should be added to struct tags.
 // This list should not include `json` as the `json` tag key is always
-// defined for each field.
+// defined for each field.  Each key may include a format, for the tag's
+// value, and options, see ParseTagKey, e.g. db:snake or yaml:kebab,omitempty.
 func (t *Transmogrifier) SetTagKeys(v []string) error {
-	t.tagKeys = make([]string, len(v))
-	n := copy(t.tagKeys, v)
-	if n != len(v) {
-		return ShortWriteError{n: len(v), written: n, operation: "SetTagKeys"}
+	keys, err := parseTagKeys(v)
+	if err != nil {
+		return err
 	}
+	t.tagKeys = keys
 	return nil
 }
 
+// AddTagKey adds an additional key to the struct tags.  This allows for
+// custom formats of the tag's value.
+func (t *Transmogrifier) AddTagKey(k TagKey) {
+	t.tagKeys = append(t.tagKeys, k)
+}
+
+// addFormatTagKey adds the tag key for the input format, if it has one and it
+// isn't already one of the tag keys.
+func (t *Transmogrifier) addFormatTagKey() {
+	key := t.InputFormat.tagKey()
+	if key == "" {
+		return
+	}
+	for _, k := range t.tagKeys {
+		if k.Key == key {
+			return
+		}
+	}
+	t.tagKeys = append(t.tagKeys, TagKey{Key: key})
+}
+
+// parseTagKeys parses the tag key definitions.
+func parseTagKeys(v []string) ([]TagKey, error) {
+	keys := make([]TagKey, 0, len(v))
+	for _, s := range v {
+		k, err := ParseTagKey(s)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
 // Gen generates the struct definitions and outputs it to W.
 func (t *Transmogrifier) Gen() error {
+	if t.GenTest && t.tw == nil {
+		return fmt.Errorf("GenTest is set but no test writer was set")
+	}
+	if t.GenTest && t.From != Samples {
+		return fmt.Errorf("GenTest requires the input to be samples")
+	}
+	if t.GenTest && t.Format != GoSource {
+		return fmt.Errorf("GenTest requires the output to be Go")
+	}
+	if t.ew != nil && t.From != HAR {
+		return fmt.Errorf("an endpoint writer requires the input to be a HAR")
+	}
 	var buff bytes.Buffer
 	_, err := buff.ReadFrom(t.r)
 	if err != nil {
 		return err
 	}
-	if t.WriteJSON {
-		var n int
-		n, err = t.jw.Write(buff.Bytes())
-		if err != nil {
-			return err
-		}
-		if n != buff.Len() {
-			return ShortWriteError{n: buff.Len(), written: n, operation: "JSON to file"}
-		}
-	}
-	var def interface{}
-	err = json.Unmarshal(buff.Bytes(), &def)
+	raw, c, err := t.Compression.decompress(buff.Bytes())
 	if err != nil {
 		return err
 	}
-	switch d := def.(type) {
-	case []interface{}:
-		def = d[0]
+	if c != Uncompressed {
+		buff.Reset()
+		// compressed JSON is rarely meant to be read, so it is
+		// pretty-printed, as the other input formats are
+		if t.InputFormat == JSONInput {
+			err = json.Indent(&buff, bytes.TrimSpace(raw), "", "\t")
+			if err != nil {
+				return err
+			}
+			buff.WriteByte('\n')
+		} else {
+			buff.Write(raw)
+		}
 	}
-	buff.Reset()
-	var wg sync.WaitGroup
-	// Write the package and import stuff to the buffer
-	n, err := buff.WriteString(fmt.Sprintf("package %s\n\n", t.pkg))
+	// input that isn't JSON is converted to JSON, which is what is
+	// written, verified, and tested.
+	if t.InputFormat != JSONInput {
+		b, err := t.InputFormat.toJSON(buff.Bytes())
+		if err != nil {
+			return err
+		}
+		buff.Reset()
+		buff.Write(b)
+		t.addFormatTagKey()
+	}
+	def, order, err := decodeOrdered(buff.Bytes())
 	if err != nil {
 		return err
 	}
-	if n != (10 + len(t.pkg)) {
-		return ShortWriteError{n: len(t.pkg), written: n, operation: "package name to buffer"}
-	}
-
-	if t.ImportJSON {
-		n, err = buff.WriteString("import (\n\t\"encoding/json\"\n)\n\n")
+	// data is the JSON that the types are generated from: the whole input
+	// or, if a path was set, the node it selects.
+	data := buff.Bytes()
+	docPath := rootPath(def)
+	if t.path != nil {
+		if t.From != Samples {
+			return fmt.Errorf("a path requires the input to be samples")
+		}
+		def, order, docPath, err = selectPath(def, order, t.path)
 		if err != nil {
 			return err
 		}
-		if n != 29 {
-			return ShortWriteError{n: 29, written: n, operation: "import to buffer"}
+		data, err = json.MarshalIndent(orderedValue(def, order), "", "\t")
+		if err != nil {
+			return err
 		}
 	}
+	samples, orders := getSamples(def, order)
+	t.imports = nil
+	t.typeNames = nil
+	t.err = nil
+	if t.ImportJSON {
+		t.addImport("encoding/json")
+	}
+	var defs []TypeDef
 	// create the work queue and the result chan
 	q := queue.NewQ(2)
-	result := make(chan []byte)
+	result := make(chan TypeDef)
 	// if MapType, process as a map type
 	// and enqueue the first item
+	if t.MapType && t.From != Samples {
+		return fmt.Errorf("MapType requires the input to be samples")
+	}
+	// root is the type being generated; it is nil if there is more than
+	// one
+	var root *jsonType
+	// enums are the enums that aren't used by a struct's field
+	var enums []TypeDef
+	// endpoints are the types generated for the endpoints of a HAR
+	var endpoints []harRoot
+	// roots are the types generated from the input, which may be pages
+	var roots []pageRoot
 	if t.MapType {
-		// if it isn't a map, return an error as this only supports maps
-		if reflect.TypeOf(def).Kind() != reflect.Map {
-			return fmt.Errorf("GenMapType error: expected a map, got %s", reflect.TypeOf(def).Kind())
-		}
-		// extract the element to use as the basis point for defining the struct
-		//
-		m := reflect.ValueOf(def)
-		keys := m.MapKeys()
-		val := m.MapIndex(keys[0])
-		// it it contains a slice, get an element from the slice
-		if val.Elem().Kind() == reflect.Slice {
-			buff.WriteString(fmt.Sprintf("type %s map[string][]%s\n\n", t.name, t.structName))
-			val = val.Elem().Index(0)
-		} else {
-			buff.WriteString(fmt.Sprintf("type %s map[string]%s\n\n", t.name, t.structName))
+		name := t.typeName(t.name)
+		structName := t.typeName(t.structName)
+		decl, typ, err := mapTypeDef(name, structName, samples, orders)
+		if err != nil {
+			return err
+		}
+		if t.InputFormat == TOMLInput {
+			typ.typeTOML()
+		}
+		typ.foldRecursive(nil)
+		defs = append(defs, decl)
+		root = &jsonType{kind: objectKind, name: name, mapOf: typ}
+		if strings.HasPrefix(decl.Type, "map[string][]") {
+			root.mapOf = &jsonType{kind: arrayKind, elem: typ}
+		}
+		q.Enqueue(newStructDef(structName, typ, docPath+".*"))
+	} else if t.From == OpenAPI {
+		roots, err := t.openAPIRoots(def, order)
+		if err != nil {
+			return err
 		}
-		q.Enqueue(newStructDef(t.structName, val.Elem()))
-		goto DEFINE
+		// string enums are named first, so that the fields that use
+		// them use their name
+		isEnum := make([]bool, len(roots))
+		for i, r := range roots {
+			if vals := r.typ.enumValues(t.EnumThreshold); vals != nil && r.typ.declared && r.typ.name == "" {
+				r.typ.name = t.typeName(t.getNamer().TypeName(r.name))
+				enums = append(enums, t.defineEnum(r.typ.name, vals))
+				isEnum[i] = true
+			}
+		}
+		for i, r := range roots {
+			switch {
+			case isEnum[i]:
+			case r.body && r.typ.name != "":
+				// the body's schema is a component, or the same
+				// as another body's, which has been defined
+				name := t.typeName(t.getNamer().TypeName(r.name))
+				defs = append(defs, TypeDef{Name: name, Kind: "alias", Type: r.typ.name, Source: fmt.Sprintf("type %s = %s", name, r.typ.name), children: []string{r.typ.name}})
+			case r.typ.kind == objectKind && r.typ.mapOf == nil && r.typ.name == "":
+				q.Enqueue(newStructDef(t.typeName(t.getNamer().TypeName(r.name)), r.typ, "$"))
+			case r.body:
+				// e.g. an array of a component
+				name := t.typeName(t.getNamer().TypeName(r.name))
+				if def, ok := t.enqueueRoot(q, pageRoot{name: name, typ: r.typ, path: "$", item: r.name + "Item"}); ok {
+					defs = append(defs, def)
+				}
+			}
+		}
+		if q.IsEmpty() && len(defs) == 0 {
+			return fmt.Errorf("the OpenAPI document doesn't have any object schemas")
+		}
+	} else if t.From == HAR {
+		eps, err := harEndpoints(def)
+		if err != nil {
+			return err
+		}
+		for _, e := range eps {
+			typ := inferType(e.samples, e.orders)
+			if obj := typ.object(); obj != nil {
+				obj.foldRecursive(nil)
+			}
+			if e.isArray {
+				typ = &jsonType{kind: arrayKind, elem: typ}
+			}
+			name := t.typeName(t.getNamer().TypeName(e.typeName()))
+			endpoints = append(endpoints, harRoot{endpoint: e.String(), name: name, bodies: e.bodies})
+			// the objects in an array, or page, are named after
+			// the resource, e.g. User for GET /users
+			item := t.singularize(e.resource())
+			if item == "" {
+				item = "item"
+			}
+			roots = append(roots, pageRoot{name: name, typ: typ, path: "$", item: item})
+		}
+		if len(roots) == 0 {
+			return fmt.Errorf("the HAR doesn't have any successful responses with JSON content")
+		}
+	} else {
+		var typ *jsonType
+		switch t.From {
+		case Schema:
+			typ, err = schemaType(def, order)
+			if err != nil {
+				return err
+			}
+			if typ.kind != objectKind || typ.mapOf != nil {
+				return fmt.Errorf("expected the schema to declare an object with properties")
+			}
+		default:
+			typ = inferType(samples, orders)
+			if typ.kind != objectKind {
+				return fmt.Errorf("expected a JSON object, got %s", typ.kind)
+			}
+			if t.InputFormat == TOMLInput {
+				typ.typeTOML()
+			}
+			typ.foldRecursive(nil)
+		}
+		root = typ
+		roots = append(roots, pageRoot{name: t.typeName(t.name), typ: typ, path: docPath})
 	}
-
+	defs = append(defs, t.enqueueRoots(q, roots)...)
 	// start the worker
-	// send initial work item
-	q.Enqueue(newStructDef(t.name, reflect.ValueOf(def)))
-
-DEFINE:
 	go func() {
-		defineStruct(q, t.tagKeys, result, &wg)
+		t.defineStruct(q, result)
 	}()
 	// collect the results until the resCh is closed
 	for {
@@ -203,38 +469,201 @@ DEFINE:
 		if !ok {
 			break
 		}
-		// TODO: returned values are being ignored; should n be checked here? err will always be nil
-		buff.Write(val)
+		defs = append(defs, val)
+	}
+	if t.err != nil {
+		return t.err
+	}
+	defs = append(defs, enums...)
+	m := Model{Name: defs[0].Name, Package: t.pkg, Types: orderTypeDefs(defs, t.TypeOrder)}
+	for imp := range t.imports {
+		m.Imports = append(m.Imports, imp)
+	}
+	sort.Strings(m.Imports)
+	var b []byte
+	switch t.Format {
+	case JSONSchema:
+		if root == nil {
+			return fmt.Errorf("JSON Schema output requires a single type")
+		}
+		b, err = t.jsonSchema(root)
+	default:
+		b, err = t.render(m)
+	}
+	if err != nil {
+		return err
+	}
+	if t.Verify {
+		err = t.verify(b, data, m, endpoints)
+		if err != nil {
+			return err
+		}
 	}
-	fmtd, err := format.Source(buff.Bytes())
+	// everything is generated before anything is written, so that
+	// nothing is written if any of it fails
+	var tb []byte
+	if t.GenTest {
+		_, isArray := def.([]interface{})
+		tb, err = t.genTest(m.Types[0].Name, data, isArray)
+		if err != nil {
+			return err
+		}
+	}
+	if t.WriteJSON {
+		n, err := t.jw.Write(buff.Bytes())
+		if err != nil {
+			return err
+		}
+		if n != buff.Len() {
+			return ShortWriteError{n: buff.Len(), written: n, operation: "JSON to file"}
+		}
+	}
+	if t.ew != nil {
+		return t.writeEndpoints(m, endpoints)
+	}
+	n, err := t.w.Write(b)
 	if err != nil {
 		return err
 	}
-	n, err = t.w.Write(fmtd)
+	if n != len(b) {
+		return ShortWriteError{n: len(b), written: n, operation: "generated code"}
+	}
+	if !t.GenTest {
+		return nil
+	}
+	n, err = t.tw.Write(tb)
 	if err != nil {
 		return err
 	}
-	if n != len(fmtd) {
-		return ShortWriteError{n: len(fmtd), written: n, operation: "formatted Go code"}
+	if n != len(tb) {
+		return ShortWriteError{n: len(tb), written: n, operation: "generated test"}
 	}
 	return nil
 }
 
 type structDef struct {
 	name string
-	val  reflect.Value
-	buff bytes.Buffer
+	typ  *jsonType
+	// typeParams is the type parameter list of a generic struct, e.g.
+	// T any.
+	typeParams string
+	// path is the JSON path of the objects that the struct defines.
+	path string
+	// ancestors are the types of the structs that enclose the struct.
+	ancestors []*jsonType
+	buff      bytes.Buffer
+	fields    []FieldDef
+	// children are the names of the structs defined for the struct's
+	// fields.
+	children []string
+	// imports are the packages that the struct's fields use.
+	imports []string
+	// decls are the definitions of types used by the struct's fields,
+	// other than structs, that follow the struct definition.
+	decls []TypeDef
 }
 
-func newStructDef(name string, val reflect.Value) structDef {
-	s := structDef{name: name, val: val}
-	s.buff.WriteString(fmt.Sprintf("type %s struct {\n", name))
+func newStructDef(name string, typ *jsonType, path string) structDef {
+	return newGenericStructDef(name, "", typ, path)
+}
+
+// newGenericStructDef returns the structDef of a struct that has the type
+// parameters, e.g. T any; if there aren't any, it isn't generic.
+func newGenericStructDef(name, typeParams string, typ *jsonType, path string) structDef {
+	typ.name = name
+	s := structDef{name: name, typ: typ, typeParams: typeParams, path: path}
+	if typeParams != "" {
+		s.buff.WriteString(fmt.Sprintf("type %s[%s] struct {\n", name, typeParams))
+	} else {
+		s.buff.WriteString(fmt.Sprintf("type %s struct {\n", name))
+	}
 	return s
 }
 
-func (s *structDef) Bytes() []byte {
-	s.buff.WriteString("}\n\n")
-	return s.buff.Bytes()
+// child returns the structDef for a struct, defined for one of the struct's
+// fields, and adds it to the struct's children.
+func (s *structDef) child(name string, typ *jsonType, path string) structDef {
+	c := newStructDef(name, typ, path)
+	c.ancestors = append(append([]*jsonType{}, s.ancestors...), s.typ)
+	s.children = append(s.children, name)
+	return c
+}
+
+// ref adds a type that has already been defined, e.g. a component, that one
+// of the struct's fields refers to, to the struct's children, unless it is
+// the struct or encloses it.
+func (s *structDef) ref(name string, typ *jsonType) {
+	if typ == s.typ || isAncestor(s.ancestors, typ) {
+		return
+	}
+	s.children = append(s.children, name)
+}
+
+// typeDefs returns the struct's definition followed by the definitions in
+// decls.
+func (s *structDef) typeDefs() []TypeDef {
+	s.buff.WriteString("}\n")
+	def := TypeDef{Name: s.name, Kind: "struct", TypeParams: s.typeParams, Fields: s.fields, Source: formatSource(s.buff.Bytes()), children: s.children, imports: s.imports}
+	return append([]TypeDef{def}, s.decls...)
+}
+
+// getSamples returns the samples within the decoded JSON, along with their
+// key orders.  If the JSON is an array, each of its elements is a sample of
+// the type being defined, otherwise the JSON itself is the only sample.
+func getSamples(def interface{}, order *keyOrder) ([]interface{}, []*keyOrder) {
+	switch d := def.(type) {
+	case []interface{}:
+		orders := make([]*keyOrder, len(d))
+		for i := range d {
+			orders[i] = order.elem(i)
+		}
+		return d, orders
+	}
+	return []interface{}{def}, []*keyOrder{order}
+}
+
+// mapTypeDef returns the definition of a map[string]T or map[string][]T
+// type, where T is named structName, along with the inferred type of T.  The
+// values of every key, in every sample, are used to infer T.
+func mapTypeDef(typeName, structName string, samples []interface{}, orders []*keyOrder) (decl TypeDef, typ *jsonType, err error) {
+	var vals []interface{}
+	var valOrders []*keyOrder
+	for i, sample := range samples {
+		m, ok := sample.(map[string]interface{})
+		if !ok {
+			return decl, nil, fmt.Errorf("GenMapType error: expected a map, got %s", inferType([]interface{}{sample}, nil).kind)
+		}
+		var o *keyOrder
+		if i < len(orders) {
+			o = orders[i]
+		}
+		// use the source order of the keys, when known, so that the
+		// order of the values is deterministic
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if o != nil {
+			keys = o.keys
+		}
+		for _, k := range keys {
+			vals = append(vals, m[k])
+			valOrders = append(valOrders, o.child(k))
+		}
+	}
+	typ = inferType(vals, valOrders)
+	// it it contains a slice, the elements are the basis for the struct
+	decl = TypeDef{Name: typeName, Kind: "map", Type: "map[string]" + structName}
+	if typ.kind == arrayKind && typ.elem != nil {
+		decl.Type = "map[string][]" + structName
+		typ = typ.elem
+	}
+	decl.Source = fmt.Sprintf("type %s %s", typeName, decl.Type)
+	if typ.kind != objectKind {
+		return TypeDef{}, nil, fmt.Errorf("GenMapType error: expected the map's values to be objects, got %s", typ.kind)
+	}
+	return decl, typ, nil
 }
 
 // GenMapType unmarshals JSON-encoded data that is in the form of
@@ -256,53 +685,46 @@ func GenMapType(typeName, name string, tagKeys []string, data []byte) ([]byte, e
 	if err != nil {
 		return nil, err
 	}
-	switch d := def.(type) {
-	case []interface{}:
-		def = d[0]
+	keys, err := parseTagKeys(tagKeys)
+	if err != nil {
+		return nil, err
 	}
-	// if it isn't a map, return an error as this only supports maps
-	if reflect.TypeOf(def).Kind() != reflect.Map {
-		return nil, fmt.Errorf("GenMapType error: expected a map, got %s", reflect.TypeOf(def).Kind())
+	t := &Transmogrifier{tagKeys: keys}
+	typeName = t.typeName(typeName)
+	name = t.typeName(name)
+	samples, orders := getSamples(def, nil)
+	decl, typ, err := mapTypeDef(typeName, name, samples, orders)
+	if err != nil {
+		return nil, err
 	}
-	// extract the element to use as the basis point for defining the struct
-	//
-	m := reflect.ValueOf(def)
-	keys := m.MapKeys()
-	val := m.MapIndex(keys[0])
-
+	typ.foldRecursive(nil)
 	var buff bytes.Buffer
-	// it it contains a slice, get an element from the slice
-	if val.Elem().Kind() == reflect.Slice {
-		buff.WriteString(fmt.Sprintf("type %s map[string][]%s\n\n", typeName, name))
-		val = val.Elem().Index(0)
-	} else {
-		buff.WriteString(fmt.Sprintf("type %s map[string]%s\n\n", typeName, name))
-	}
-	var wg sync.WaitGroup
+	buff.WriteString(decl.Source + "\n\n")
 	q := queue.NewQ(2)
-	result := make(chan []byte)
+	result := make(chan TypeDef)
 	// create first work item and add to the queue
-	s := newStructDef(name, val.Elem())
+	s := newStructDef(name, typ, rootPath(def)+".*")
 	q.Enqueue(s)
 	// start the worker &  send initial work item
 	go func() {
-		defineStruct(q, tagKeys, result, &wg)
+		t.defineStruct(q, result)
 	}()
 	// collect the results until the resCh is closed
-	var i int
 	for {
-		i++
 		val, ok := <-result
 		if !ok {
 			break
 		}
 		// TODO handle error/short read
-		buff.Write(val)
+		buff.WriteString(val.Source + "\n\n")
+	}
+	if t.err != nil {
+		return nil, t.err
 	}
 	return buff.Bytes(), nil
 }
 
-func defineStruct(q *queue.Queue, tagKeys []string, result chan []byte, wg *sync.WaitGroup) {
+func (t *Transmogrifier) defineStruct(q *queue.Queue, result chan TypeDef) {
 	for {
 		if q.IsEmpty() {
 			break
@@ -312,101 +734,175 @@ func defineStruct(q *queue.Queue, tagKeys []string, result chan []byte, wg *sync
 			break
 		}
 		s := tmp.(structDef)
-		var sv stringValues = s.val.MapKeys()
-		sort.Sort(sv)
-		for _, key := range sv {
-			k, tag := getFieldName(key)
-			val := s.val.MapIndex(key)
-			typ := getValueKind(val)
-			// maps are embedded structs
-			if typ == reflect.Map.String() {
-				tmp := newStructDef(k, val.Elem())
-				q.Enqueue(tmp)
-				s.buff.WriteString(fmt.Sprintf("\t%s `json:%q`\n", k, tag))
-				continue
+		fields := s.typ.orderedFields(t.FieldOrder)
+		names := t.fieldNames(fields)
+		for i, f := range fields {
+			k := names[i]
+			var typ, opt string
+			var embedded bool
+			switch obj := f.typ.object(); {
+			case f.typ == obj && f.typ.name != "":
+				// an object that has already been defined is either a
+				// reference to an enclosing type, or to one that
+				// contains this one, so it must be a pointer, or to
+				// a shared definition, e.g. from a schema
+				typ = f.typ.name
+				if isAncestor(s.ancestors, f.typ) || f.typ.reaches(s.typ, nil) {
+					typ = "*" + typ
+				}
+				s.ref(f.typ.name, f.typ)
+			case f.typ == obj:
+				// objects are embedded structs, unless the type name
+				// differs from the field name
+				typ = t.typeName(t.getNamer().TypeName(declName(f.typ, f.key)))
+				q.Enqueue(s.child(typ, f.typ, jsonPath(s.path, f.key)))
+				embedded = typ == k
+			case obj != nil:
+				// an array, or map, of objects is a []T, or
+				// map[string]T; T is named using the singular form of
+				// the key
+				name := obj.name
+				if name == "" {
+					name = t.typeName(t.getNamer().TypeName(declName(obj, t.singularize(f.key))))
+					q.Enqueue(s.child(name, obj, arrayPath(jsonPath(s.path, f.key), f.typ)))
+				} else {
+					s.ref(name, obj)
+				}
+				typ = f.typ.goType(name)
+			default:
+				typ = f.typ.goType(k)
+				if t.StringEncoded {
+					if enc := f.typ.stringEncoded(); enc != "" {
+						typ, opt = enc, "string"
+					}
+				}
+				if opt == "" && (t.EnumThreshold > 0 || f.typ.declared) {
+					if vals := f.typ.enumValues(t.EnumThreshold); vals != nil {
+						// a shared enum, e.g. from a schema, is
+						// only defined once
+						typ = f.typ.name
+						if typ == "" {
+							typ = t.typeName(t.getNamer().TypeName(declName(f.typ, f.key)))
+							f.typ.name = typ
+							s.decls = append(s.decls, t.defineEnum(typ, vals))
+						} else {
+							s.ref(typ, f.typ)
+						}
+					}
+				}
+			}
+			if strings.Contains(typ, "time.Time") {
+				t.addImport("time")
+				s.imports = append(s.imports, "time")
+			}
+			var doc string
+			if t.Comments {
+				doc = fieldDoc(f, s.typ, jsonPath(s.path, f.key))
+				for _, line := range strings.Split(doc, "\n") {
+					s.buff.WriteString(fmt.Sprintf("\t// %s\n", line))
+				}
 			}
-			// a slicemap is a signal that it is a []T which means pluralize
-			// the field name and generate the embedded struct
-			if typ == "slicemap" {
-				tmp := newStructDef(k, val.Elem().Index(0).Elem())
-				q.Enqueue(tmp)
-				s.buff.WriteString(fmt.Sprintf("\t%ss []%s ", k, k))
-				s.buff.WriteString(defineFieldTags(tag, tagKeys))
-				s.buff.WriteRune('\n')
-				continue
+			if embedded {
+				s.buff.WriteString(fmt.Sprintf("\t%s ", k))
+			} else {
+				s.buff.WriteString(fmt.Sprintf("\t%s %s ", k, typ))
 			}
-			s.buff.WriteString(fmt.Sprintf("\t%s %s ", k, typ))
-			s.buff.WriteString(defineFieldTags(tag, tagKeys))
+			tf := newTagField(f, s.typ, k, typ)
+			tags, err := defineFieldTags(tf, opt, t.tagKeys)
+			// a JSON Schema doesn't have tags, so any key can be used
+			if err != nil && t.err == nil && t.Format != JSONSchema {
+				t.err = err
+			}
+			s.buff.WriteString(tags)
 			s.buff.WriteRune('\n')
+			s.fields = append(s.fields, FieldDef{TagField: tf, Embedded: embedded, Tag: tags, Doc: doc})
+		}
+		for _, def := range s.typeDefs() {
+			result <- def
 		}
-		result <- s.Bytes()
 	}
 	close(result)
 }
 
-// defineFieldTags defines the json field tag, along with any additional
-// tag key:"value" pairs using the received keys, if any.
-func defineFieldTags(value string, keys []string) string {
-	var tag string
-	tag = fmt.Sprintf("`json:%q", value)
-	for _, key := range keys {
-		tag = fmt.Sprintf("%s %s:%q", tag, key, value)
+// AddIrregular adds a plural, and its singular form, that the regular English
+// pluralization rules don't handle, e.g. AddIrregular("person", "people").
+// The singular form is used to name the element type of an array.
+// Uncountable words can be added by using the same word for both.
+func (t *Transmogrifier) AddIrregular(singular, plural string) {
+	if t.inflect == nil {
+		t.inflect = newInflector()
 	}
-	return fmt.Sprintf("%s`", tag)
+	t.inflect.addIrregular(singular, plural)
+}
 
+// singularize returns the singular form of s.
+func (t *Transmogrifier) singularize(s string) string {
+	if t.inflect == nil {
+		t.inflect = newInflector()
+	}
+	t.inflect.initialisms = t.initialisms
+	return t.inflect.singularize(s)
 }
 
-func getValueKind(val reflect.Value) string {
-	// if the value is nil, return interface{}; what type a nil should be
-	// cannot be accurately determined.
-	if val.IsNil() {
-		return "interface{}"
-	}
-	switch val.Elem().Type().Kind() {
-	case reflect.Float64:
-		v := val.Elem().Float()
-		if v == float64(int64(v)) {
-			return reflect.Int.String()
-		}
-		return reflect.Float64.String()
-	case reflect.Slice:
-		if val.Elem().Len() == 0 {
-			return fmt.Sprint("[]interface{}")
-		}
-		v := val.Elem().Index(0).Elem()
-		switch v.Type().Kind() {
-		case reflect.Float64:
-			vv := v.Float()
-			if vv == float64(int64(vv)) {
-				return fmt.Sprintf("[]%s", reflect.Int.String())
-			}
-			return fmt.Sprintf("[]%s", reflect.Float64.String())
-		case reflect.Map:
-			return "slicemap"
-		}
-		return fmt.Sprintf("[]%s", v.Type().Kind().String())
+// addImport adds the package to the imports of the generated code.
+func (t *Transmogrifier) addImport(pkg string) {
+	if t.imports == nil {
+		t.imports = map[string]struct{}{}
 	}
-	return val.Elem().Type().Kind().String()
+	t.imports[pkg] = struct{}{}
 }
 
-// getFieldName: get the field name and tag for the key.  Underscores are
-// removed and values separated by underscores have their first rune
-// uppercased, when applicable.  The first part of the FieldName is cleaned to
-// ensure that it starts with a valid character and is uppercased.
-func getFieldName(key reflect.Value) (name, tag string) {
-	tag = key.String()
-	vals := strings.Split(tag, "_")
-	for i, v := range vals {
-		if i == 0 {
-			name = cleanFieldName(v)
-			name = toUpperInitialism(name)
+// defineFieldTags defines the json field tag, along with any additional
+// tag key:"value" pairs using the received keys, if any.  If opt isn't empty,
+// it is added to the json tag as an option, e.g. `json:"value,string"`.  Keys
+// with a template whose result is empty are omitted.
+//
+// The key - is followed by a comma, `json:"-,"`, as `json:"-"` skips the
+// field.  An error is returned for a key that encoding/json doesn't accept
+// as a name, see isJSONTagName, as it would decode the field's name instead.
+func defineFieldTags(f TagField, opt string, keys []TagKey) (string, error) {
+	if f.Key != "-" && !isJSONTagName(f.Key) {
+		return "", fmt.Errorf("the key %q can't be the name in a json tag, so encoding/json can't decode it into a field", f.Key)
+	}
+	name := f.Key
+	if opt != "" || name == "-" {
+		name += "," + opt
+	}
+	tag := fmt.Sprintf("`json:%q", name)
+	for _, key := range keys {
+		v, err := key.value(f)
+		if err != nil {
+			return "", err
+		}
+		if v == "" && key.Template != nil {
 			continue
 		}
-		name = fmt.Sprintf("%s%s", name, toUpperInitialism(strings.Title(v)))
+		tag = fmt.Sprintf("%s %s:%q", tag, key.Key, v)
+	}
+	return fmt.Sprintf("%s`", tag), nil
+
+}
+
+// isJSONTagName returns whether encoding/json accepts s as the name in a json
+// tag: it isn't empty and it only has letters, digits, spaces, and
+// punctuation other than quotes, backslashes, and commas.
+func isJSONTagName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r):
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return false
+		}
 	}
-	return name, tag
+	return true
 }
 
+// cleanFieldName discards any runes, at the start of s, that are invalid at
+// the start of a field name.  The first remaining rune is uppercased, or
+// converted to its word equivalent if it is a number.
 func cleanFieldName(s string) string {
 	var first string
 	var pos int
@@ -507,11 +1003,3 @@ var commonInitialisms = map[string]struct{}{
 	"VM":    struct{}{},
 	"XML":   struct{}{},
 }
-
-func toUpperInitialism(s string) string {
-	tmp := strings.ToUpper(s)
-	if _, ok := commonInitialisms[tmp]; ok {
-		return tmp
-	}
-	return s
-}
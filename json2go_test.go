@@ -3,6 +3,8 @@ package json2go
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -182,7 +184,7 @@ var sliceMap = []byte(`{
 	]
 }`)
 
-var expectedSliceMap = "type SliceMap struct {\n\tFoos []Foo `json:\"foo\"`\n}\n\ntype Foo struct {\n\tBar    string `json:\"bar\"`\n\tFooBar string `json:\"foo_bar\"`\n}\n"
+var expectedSliceMap = "type SliceMap struct {\n\tFoo []Foo `json:\"foo\"`\n}\n\ntype Foo struct {\n\tBar    string `json:\"bar\"`\n\tFooBar string `json:\"foo_bar\"`\n}\n"
 var expectedSliceMapPkg = fmt.Sprintf("package main\n\n%s", expectedSliceMap)
 
 func TestArrays(t *testing.T) {
@@ -284,17 +286,41 @@ func TestMapSliceType(t *testing.T) {
 
 func TestDefineFieldTags(t *testing.T) {
 	tests := []struct {
-		keys     []string
+		keys     []TagKey
 		value    string
+		opt      string
 		expected string
 	}{
-		{nil, "field", "`json:\"field\"`"},
-		{[]string{}, "field", "`json:\"field\"`"},
-		{[]string{"xml"}, "field", "`json:\"field\" xml:\"field\"`"},
-		{[]string{"xml", "yaml", "db"}, "field", "`json:\"field\" xml:\"field\" yaml:\"field\" db:\"field\"`"},
+		{nil, "field", "", "`json:\"field\"`"},
+		{[]TagKey{}, "field", "", "`json:\"field\"`"},
+		{[]TagKey{{Key: "xml"}}, "field", "", "`json:\"field\" xml:\"field\"`"},
+		{[]TagKey{{Key: "xml"}, {Key: "yaml"}, {Key: "db"}}, "field", "", "`json:\"field\" xml:\"field\" yaml:\"field\" db:\"field\"`"},
+		{nil, "field", "string", "`json:\"field,string\"`"},
+		{[]TagKey{{Key: "xml"}}, "field", "string", "`json:\"field,string\" xml:\"field\"`"},
+		{[]TagKey{{Key: "db", Format: SnakeCase}, {Key: "yaml", Format: KebabCase, Options: "omitempty"}}, "hOffset", "", "`json:\"hOffset\" db:\"h_offset\" yaml:\"h-offset,omitempty\"`"},
+		{[]TagKey{{Key: "xml", Format: CamelCase, Options: "attr"}}, "h_offset", "", "`json:\"h_offset\" xml:\"hOffset,attr\"`"},
+		{nil, "-", "", "`json:\"-,\"`"},
+		{nil, "-", "string", "`json:\"-,string\"`"},
+		{nil, "a b/c[0]$", "", "`json:\"a b/c[0]$\"`"},
+		{nil, "", "", ""},
+		{nil, "foo,bar", "", ""},
+		{nil, "q\"x", "", ""},
+		{nil, "a\nb", "", ""},
+		{nil, "a`b", "", ""},
+		{nil, "🚀", "", ""},
 	}
 	for i, test := range tests {
-		tag := defineFieldTags(test.value, test.keys)
+		tag, err := defineFieldTags(TagField{Key: test.value}, test.opt, test.keys)
+		if test.expected == "" {
+			if err == nil || !strings.Contains(err.Error(), "can't be the name in a json tag") {
+				t.Errorf("%d: got error %v, want one for the key %q", i, err, test.value)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
 		if tag != test.expected {
 			t.Errorf("%d: got %q, want %q", i, tag, test.expected)
 		}
@@ -451,3 +477,310 @@ func TestEmptySlice(t *testing.T) {
 		t.Errorf("got %q want %q", buff.String(), expected)
 	}
 }
+
+var stringEncoded = []byte(`[
+	{
+		"id": "42",
+		"price": "1.5",
+		"active": "true",
+		"name": "Marvin",
+		"count": 3,
+		"padded": " 42"
+	},
+	{
+		"id": "9001",
+		"price": "2",
+		"active": "false",
+		"name": "42",
+		"count": 4,
+		"padded": "7"
+	}
+]`)
+
+func TestStringEncoded(t *testing.T) {
+	tests := []struct {
+		stringEncoded bool
+		expected      string
+	}{
+		{false, "package main\n\ntype Test struct {\n\tActive string `json:\"active\"`\n\tCount  int    `json:\"count\"`\n\tID     string `json:\"id\"`\n\tName   string `json:\"name\"`\n\tPadded string `json:\"padded\"`\n\tPrice  string `json:\"price\"`\n}\n"},
+		{true, "package main\n\ntype Test struct {\n\tActive bool    `json:\"active,string\"`\n\tCount  int     `json:\"count\"`\n\tID     int64   `json:\"id,string\"`\n\tName   string  `json:\"name\"`\n\tPadded string  `json:\"padded\"`\n\tPrice  float64 `json:\"price,string\"`\n}\n"},
+	}
+	for i, test := range tests {
+		r := bytes.NewReader(stringEncoded)
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("test", r, &buff)
+		calvin.StringEncoded = test.stringEncoded
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if buff.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, buff.String(), test.expected)
+		}
+	}
+}
+
+var enums = []byte(`[
+	{"name": "Marvin", "status": "active", "role": "android"},
+	{"name": "Arthur", "status": "suspended", "role": "human"},
+	{"name": "Ford", "status": "active", "role": "betelgeusian"},
+	{"name": "Zaphod", "status": "deleted", "role": "betelgeusian"}
+]`)
+
+func TestEnums(t *testing.T) {
+	tests := []struct {
+		threshold int
+		strict    bool
+		expected  string
+	}{
+		{0, false, "package main\n\ntype Test struct {\n\tName   string `json:\"name\"`\n\tRole   string `json:\"role\"`\n\tStatus string `json:\"status\"`\n}\n"},
+		{3, false, "package main\n\ntype Test struct {\n\tName   string `json:\"name\"`\n\tRole   Role   `json:\"role\"`\n\tStatus Status `json:\"status\"`\n}\n\ntype Role string\n\nconst (\n\tRoleAndroid      Role = \"android\"\n\tRoleBetelgeusian Role = \"betelgeusian\"\n\tRoleHuman        Role = \"human\"\n)\n\n// IsValid reports whether v is a known Role value.\nfunc (v Role) IsValid() bool {\n\tswitch v {\n\tcase RoleAndroid, RoleBetelgeusian, RoleHuman:\n\t\treturn true\n\t}\n\treturn false\n}\n\ntype Status string\n\nconst (\n\tStatusActive    Status = \"active\"\n\tStatusDeleted   Status = \"deleted\"\n\tStatusSuspended Status = \"suspended\"\n)\n\n// IsValid reports whether v is a known Status value.\nfunc (v Status) IsValid() bool {\n\tswitch v {\n\tcase StatusActive, StatusDeleted, StatusSuspended:\n\t\treturn true\n\t}\n\treturn false\n}\n"},
+		{1, true, "package main\n\ntype Test struct {\n\tName   string `json:\"name\"`\n\tRole   string `json:\"role\"`\n\tStatus string `json:\"status\"`\n}\n"},
+		{3, true, "package main\n\nimport (\n\t\"encoding/json\"\n\t\"fmt\"\n)\n\ntype Test struct {\n\tName   string `json:\"name\"`\n\tRole   Role   `json:\"role\"`\n\tStatus Status `json:\"status\"`\n}\n\ntype Role string\n\nconst (\n\tRoleAndroid      Role = \"android\"\n\tRoleBetelgeusian Role = \"betelgeusian\"\n\tRoleHuman        Role = \"human\"\n)\n\n// IsValid reports whether v is a known Role value.\nfunc (v Role) IsValid() bool {\n\tswitch v {\n\tcase RoleAndroid, RoleBetelgeusian, RoleHuman:\n\t\treturn true\n\t}\n\treturn false\n}\n\n// UnmarshalJSON implements json.Unmarshaler.  Values that aren't known\n// Role values are rejected; null is a no-op, as it is for a string.\nfunc (v *Role) UnmarshalJSON(b []byte) error {\n\tif string(b) == \"null\" {\n\t\treturn nil\n\t}\n\tvar s string\n\tif err := json.Unmarshal(b, &s); err != nil {\n\t\treturn err\n\t}\n\tif !Role(s).IsValid() {\n\t\treturn fmt.Errorf(\"invalid Role value: %q\", s)\n\t}\n\t*v = Role(s)\n\treturn nil\n}\n\ntype Status string\n\nconst (\n\tStatusActive    Status = \"active\"\n\tStatusDeleted   Status = \"deleted\"\n\tStatusSuspended Status = \"suspended\"\n)\n\n// IsValid reports whether v is a known Status value.\nfunc (v Status) IsValid() bool {\n\tswitch v {\n\tcase StatusActive, StatusDeleted, StatusSuspended:\n\t\treturn true\n\t}\n\treturn false\n}\n\n// UnmarshalJSON implements json.Unmarshaler.  Values that aren't known\n// Status values are rejected; null is a no-op, as it is for a string.\nfunc (v *Status) UnmarshalJSON(b []byte) error {\n\tif string(b) == \"null\" {\n\t\treturn nil\n\t}\n\tvar s string\n\tif err := json.Unmarshal(b, &s); err != nil {\n\t\treturn err\n\t}\n\tif !Status(s).IsValid() {\n\t\treturn fmt.Errorf(\"invalid Status value: %q\", s)\n\t}\n\t*v = Status(s)\n\treturn nil\n}\n"},
+	}
+	for i, test := range tests {
+		r := bytes.NewReader(enums)
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("test", r, &buff)
+		calvin.EnumThreshold = test.threshold
+		calvin.StrictEnums = test.strict
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if buff.String() != test.expected {
+			t.Errorf("%d: got %q want %q", i, buff.String(), test.expected)
+		}
+	}
+}
+
+var comments = []byte(`{
+	"id": 1,
+	"text": "Don't Panic",
+	"parent": {
+		"id": 0,
+		"text": "Mostly harmless",
+		"parent": null
+	},
+	"children": [
+		{
+			"id": 2,
+			"text": "Share and enjoy",
+			"parent": null,
+			"children": [
+				{
+					"id": 3,
+					"text": "So long",
+					"score": 4.5,
+					"children": []
+				}
+			]
+		}
+	]
+}`)
+
+var expectedComments = "package main\n\ntype Comment struct {\n\tChildren []Comment `json:\"children\"`\n\tID       int       `json:\"id\"`\n\tParent   *Comment  `json:\"parent\"`\n\tScore    float64   `json:\"score\"`\n\tText     string    `json:\"text\"`\n}\n"
+
+func TestRecursive(t *testing.T) {
+	r := bytes.NewReader(comments)
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("Comment", r, &buff)
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expectedComments {
+		t.Errorf("got %q want %q", buff.String(), expectedComments)
+	}
+}
+
+var plurals = []byte(`{
+	"items": [{"id": 1}],
+	"categories": [{"name": "towels"}],
+	"people": [{"name": "Arthur"}],
+	"cacti": [{"spines": 42}]
+}`)
+
+func TestSingularize(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\tCacti      []Cactus   `json:\"cacti\"`\n\tCategories []Category `json:\"categories\"`\n\tItems      []Item     `json:\"items\"`\n\tPeople     []Person   `json:\"people\"`\n}\n\ntype Cactus struct {\n\tSpines int `json:\"spines\"`\n}\n\ntype Category struct {\n\tName string `json:\"name\"`\n}\n\ntype Item struct {\n\tID int `json:\"id\"`\n}\n\ntype Person struct {\n\tName string `json:\"name\"`\n}\n"
+	r := bytes.NewReader(plurals)
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	calvin.AddIrregular("cactus", "cacti")
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+var oddKeys = []byte(`{
+	"foo_bar": 1,
+	"fooBar": 2,
+	"foo bar": 3,
+	"$": "dollar",
+	"-": "dash",
+	"a/b[0]": true,
+	"日本語": "nihongo",
+	"window": {"name": {"first": "main"}},
+	"name": {"last": "Dent"}
+}`)
+
+var expectedOddKeys = "package main\n\ntype Test struct {\n\tField   string `json:\"$\"`\n\tField2  string `json:\"-,\"`\n\tAB0     bool   `json:\"a/b[0]\"`\n\tFooBar  int    `json:\"foo bar\"`\n\tFooBar2 int    `json:\"fooBar\"`\n\tFooBar3 int    `json:\"foo_bar\"`\n\tName    `json:\"name\"`\n\tWindow  `json:\"window\"`\n\tX日本語    string `json:\"日本語\"`\n}\n\ntype Name struct {\n\tLast string `json:\"last\"`\n}\n\ntype Window struct {\n\tName Name2 `json:\"name\"`\n}\n\ntype Name2 struct {\n\tFirst string `json:\"first\"`\n}\n"
+
+func TestOddKeys(t *testing.T) {
+	r := bytes.NewReader(oddKeys)
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expectedOddKeys {
+		t.Errorf("got %q want %q", buff.String(), expectedOddKeys)
+	}
+}
+
+// TestUntaggableKeys tests that keys that can't be the name in a json tag
+// are errors, rather than fields that encoding/json doesn't decode.
+func TestUntaggableKeys(t *testing.T) {
+	tests := []string{
+		`{"": 1}`,
+		`{"foo,bar": 1}`,
+		`{"q\"x": 1}`,
+		`{"a\nb": 1}`,
+		"{\"a`b\": 1}",
+		`{"a": {"🚀": 1}}`,
+	}
+	for i, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("test", strings.NewReader(test), &buff)
+		err := calvin.Gen()
+		if err == nil || !strings.Contains(err.Error(), "can't be the name in a json tag") {
+			t.Errorf("%d: got error %v, want one for the key", i, err)
+		}
+		if buff.Len() != 0 {
+			t.Errorf("%d: expected nothing to be written, got %q", i, buff.String())
+		}
+	}
+}
+
+func TestReservedNames(t *testing.T) {
+	expected := "package main\n\ntype Func struct {\n\tString `json:\"string\"`\n\tType   string `json:\"type\"`\n}\n\ntype String struct {\n\tLen int `json:\"len\"`\n}\n"
+	r := bytes.NewReader([]byte(`{"type": "func", "string": {"len": 4}}`))
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("func", r, &buff)
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+var sourceOrdered = []byte(`{
+	"name": "towel",
+	"id": 42,
+	"meta": {"zed": true, "abc": 1}
+}`)
+
+func TestSourceOrder(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\tName string `json:\"name\"`\n\tID   int    `json:\"id\"`\n\tMeta `json:\"meta\"`\n}\n\ntype Meta struct {\n\tZed bool `json:\"zed\"`\n\tAbc int  `json:\"abc\"`\n}\n"
+	r := bytes.NewReader(sourceOrdered)
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	calvin.FieldOrder = SourceOrder
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+var nestedTypes = []byte(`{
+	"zoo": {"keeper": {"name": "Pat"}},
+	"barn": {"animal": {"name": "cow"}, "stall": {"num": 1}}
+}`)
+
+func TestTypeOrder(t *testing.T) {
+	tests := []struct {
+		order    TypeOrder
+		expected []string
+	}{
+		{BreadthFirst, []string{"Test", "Barn", "Zoo", "Animal", "Stall", "Keeper"}},
+		{DepthFirst, []string{"Test", "Barn", "Animal", "Stall", "Zoo", "Keeper"}},
+		{ByName, []string{"Test", "Animal", "Barn", "Keeper", "Stall", "Zoo"}},
+	}
+	for _, test := range tests {
+		r := bytes.NewReader(nestedTypes)
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("test", r, &buff)
+		calvin.TypeOrder = test.order
+		err := calvin.SetTemplate("{{range .Types}}{{.Name}}\n{{end}}")
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", test.order, err)
+			continue
+		}
+		calvin.SkipFormat = true
+		err = calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", test.order, err)
+			continue
+		}
+		names := strings.Fields(buff.String())
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("%d: got %q want %q", test.order, names, test.expected)
+		}
+	}
+}
+
+var nestedTypesPermuted = []byte(`{
+	"barn": {"stall": {"num": 1}, "animal": {"name": "cow"}},
+	"zoo": {"keeper": {"name": "Pat"}}
+}`)
+
+func TestTypeOrderStable(t *testing.T) {
+	for _, order := range []TypeOrder{BreadthFirst, DepthFirst, ByName} {
+		var out []string
+		for _, src := range [][]byte{nestedTypes, nestedTypesPermuted} {
+			var buff bytes.Buffer
+			calvin := NewTransmogrifier("test", bytes.NewReader(src), &buff)
+			calvin.TypeOrder = order
+			err := calvin.Gen()
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", order, err)
+			}
+			out = append(out, buff.String())
+		}
+		if out[0] != out[1] {
+			t.Errorf("%d: output differs with the key order: %q and %q", order, out[0], out[1])
+		}
+	}
+}
+
+var documented = []byte(`[
+	{"name": "towel", "size": 3, "tags": ["soft"], "first name": "Arthur", "owner": {"id": 42}},
+	{"name": "a towel that is much too long to be included in its entirety", "size": 12.5, "owner": {"id": 7}}
+]`)
+
+func TestComments(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\t// example: \"Arthur\"; path: $[*][\"first name\"]; present: 1/2 (50%); length: 6\n\tFirstName string `json:\"first name\"`\n\t// example: \"towel\"; path: $[*].name; present: 2/2 (100%); length: 5-60\n\tName string `json:\"name\"`\n\t// path: $[*].owner; present: 2/2 (100%)\n\tOwner `json:\"owner\"`\n\t// example: 3; path: $[*].size; present: 2/2 (100%); range: 3-12.5\n\tSize float64 `json:\"size\"`\n\t// example: \"soft\"; path: $[*].tags; present: 1/2 (50%); length: 1\n\tTags []string `json:\"tags\"`\n}\n\ntype Owner struct {\n\t// example: 42; path: $[*].owner.id; present: 2/2 (100%); range: 7-42\n\tID int `json:\"id\"`\n}\n"
+	r := bytes.NewReader(documented)
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	calvin.Comments = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
@@ -0,0 +1,323 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// decodeJSON5 decodes the lenient JSON, JSON5 or JSONC, data, see toJSON.  On
+// top of JSON, line and block comments, trailing commas, single-quoted
+// strings, unquoted keys, hexadecimal numbers, numbers with a leading + or a
+// leading or trailing decimal point, and multi-line strings are accepted.
+// Infinity and NaN, which JSON can't represent, are strings.
+func decodeJSON5(data []byte) (interface{}, error) {
+	p := json5Parser{s: string(data), line: 1}
+	err := p.skipSpace()
+	if err != nil {
+		return nil, err
+	}
+	v, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	err = p.skipSpace()
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q after top-level value", p.s[p.i])
+	}
+	return v, nil
+}
+
+// json5Parser parses JSON5.
+type json5Parser struct {
+	s    string
+	i    int
+	line int
+}
+
+func (p *json5Parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("json5: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *json5Parser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+// skipSpace skips whitespace and comments.
+func (p *json5Parser) skipSpace() error {
+	for !p.eof() {
+		switch {
+		case p.s[p.i] == '\n':
+			p.line++
+			p.i++
+		case strings.HasPrefix(p.s[p.i:], "//"):
+			for !p.eof() && p.s[p.i] != '\n' {
+				p.i++
+			}
+		case strings.HasPrefix(p.s[p.i:], "/*"):
+			end := strings.Index(p.s[p.i+2:], "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.line += strings.Count(p.s[p.i:p.i+2+end], "\n")
+			p.i += end + 4
+		default:
+			r, n := utf8.DecodeRuneInString(p.s[p.i:])
+			if !unicode.IsSpace(r) && r != '\ufeff' {
+				return nil
+			}
+			p.i += n
+		}
+	}
+	return nil
+}
+
+// value parses the value at the current position.
+func (p *json5Parser) value() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch c := p.s[p.i]; c {
+	case '{':
+		return p.object()
+	case '[':
+		return p.array()
+	case '"', '\'':
+		return p.str()
+	}
+	start := p.i
+	for !p.eof() && strings.IndexByte(",:]}/ \t\r\n", p.s[p.i]) < 0 {
+		p.i++
+	}
+	tok := p.s[start:p.i]
+	switch tok {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	case "Infinity", "+Infinity", "-Infinity", "NaN", "+NaN", "-NaN":
+		return tok, nil
+	}
+	if n, ok := json5Number(tok); ok {
+		return n, nil
+	}
+	if tok == "" {
+		return nil, p.errorf("unexpected %q", p.s[p.i])
+	}
+	return nil, p.errorf("invalid value %q", tok)
+}
+
+// json5Number returns s as a json.Number if it is a JSON5 number.
+func json5Number(s string) (json.Number, bool) {
+	digits := strings.TrimLeft(s, "+-")
+	if len(s)-len(digits) > 1 {
+		return "", false
+	}
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		v, err := strconv.ParseInt(digits[2:], 16, 64)
+		if err != nil {
+			return "", false
+		}
+		if s[0] == '-' {
+			v = -v
+		}
+		return jsonNumber(strconv.FormatInt(v, 10))
+	}
+	if digits == "" || strings.IndexByte(".0123456789", digits[0]) < 0 {
+		return "", false
+	}
+	return jsonNumber(s)
+}
+
+// object parses an object; its keys may be unquoted and it may have a
+// trailing comma.
+func (p *json5Parser) object() (interface{}, error) {
+	p.i++
+	o := &orderedObject{}
+	for {
+		err := p.skipSpace()
+		if err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, p.errorf("unterminated object")
+		}
+		if p.s[p.i] == '}' {
+			p.i++
+			return o, nil
+		}
+		key, err := p.key()
+		if err != nil {
+			return nil, err
+		}
+		err = p.skipSpace()
+		if err != nil {
+			return nil, err
+		}
+		if p.eof() || p.s[p.i] != ':' {
+			return nil, p.errorf("expected : after key %q", key)
+		}
+		p.i++
+		err = p.skipSpace()
+		if err != nil {
+			return nil, err
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		o.set(key, v)
+		err = p.sep('}')
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// key parses an object's key: a string or an identifier.
+func (p *json5Parser) key() (string, error) {
+	if c := p.s[p.i]; c == '"' || c == '\'' {
+		v, err := p.str()
+		if err != nil {
+			return "", err
+		}
+		return v.(string), nil
+	}
+	start := p.i
+	for !p.eof() {
+		r, n := utf8.DecodeRuneInString(p.s[p.i:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		p.i += n
+	}
+	if start == p.i {
+		return "", p.errorf("expected a key, got %q", p.s[p.i])
+	}
+	return p.s[start:p.i], nil
+}
+
+// array parses an array; it may have a trailing comma.
+func (p *json5Parser) array() (interface{}, error) {
+	p.i++
+	a := []interface{}{}
+	for {
+		err := p.skipSpace()
+		if err != nil {
+			return nil, err
+		}
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.s[p.i] == ']' {
+			p.i++
+			return a, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+		err = p.sep(']')
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sep consumes the separator that follows an entry, see entrySep.
+func (p *json5Parser) sep(end byte) error {
+	err := p.skipSpace()
+	if err != nil {
+		return err
+	}
+	n, err := entrySep(p.s[p.i:], end)
+	if err != nil {
+		return p.errorf("%s", err)
+	}
+	p.i += n
+	return nil
+}
+
+// json5Escapes are the single character escapes of strings.
+var json5Escapes = map[byte]string{
+	'b': "\b", 'f': "\f", 'n': "\n", 'r': "\r", 't': "\t", 'v': "\v",
+	'0': "\x00",
+}
+
+// str parses a single, or double, quoted string.
+func (p *json5Parser) str() (interface{}, error) {
+	q := p.s[p.i]
+	p.i++
+	var buff strings.Builder
+	for {
+		if p.eof() {
+			return nil, p.errorf("unterminated string")
+		}
+		c := p.s[p.i]
+		p.i++
+		switch {
+		case c == q:
+			return buff.String(), nil
+		case c == '\n':
+			return nil, p.errorf("unterminated string")
+		case c != '\\':
+			buff.WriteByte(c)
+			continue
+		}
+		if p.eof() {
+			return nil, p.errorf("unterminated string")
+		}
+		c = p.s[p.i]
+		p.i++
+		if e, ok := json5Escapes[c]; ok {
+			buff.WriteString(e)
+			continue
+		}
+		switch c {
+		case '\n':
+			// an escaped newline continues the string on the next
+			// line
+			p.line++
+		case '\r':
+			if !p.eof() && p.s[p.i] == '\n' {
+				p.i++
+			}
+			p.line++
+		case 'x', 'u':
+			n := 2
+			if c == 'u' {
+				n = 4
+			}
+			if p.i+n > len(p.s) {
+				return nil, p.errorf("invalid escape \\%c", c)
+			}
+			r, err := strconv.ParseUint(p.s[p.i:p.i+n], 16, 32)
+			if err != nil {
+				return nil, p.errorf("invalid escape \\%c%s", c, p.s[p.i:p.i+n])
+			}
+			p.i += n
+			// a surrogate pair is two \u escapes
+			if utf16.IsSurrogate(rune(r)) && strings.HasPrefix(p.s[p.i:], "\\u") && p.i+6 <= len(p.s) {
+				lo, err := strconv.ParseUint(p.s[p.i+2:p.i+6], 16, 32)
+				if err == nil {
+					r = uint64(utf16.DecodeRune(rune(r), rune(lo)))
+					p.i += 6
+				}
+			}
+			buff.WriteRune(rune(r))
+		default:
+			// any other escaped character is itself, e.g. \' or \"
+			buff.WriteByte(c)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package json2go
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON5(t *testing.T) {
+	tests := []struct {
+		json5    string
+		expected string
+		err      string
+	}{
+		{`{"b": 1, "a": "x"}`, `{"b":1,"a":"x"}`, ""},
+		{"// settings\n{\n\t\"a\": 1, // one\n\t/* two */ \"b\": 2,\n}\n", `{"a":1,"b":2}`, ""},
+		{`{a: 1, $b: 2, _c: 3, 'd-e': 4}`, `{"a":1,"$b":2,"_c":3,"d-e":4}`, ""},
+		{`['x', 'it\'s', "say \"hi\"", 'line \
+two', '\x41é😀',]`, `["x","it's","say \"hi\"","line two","Aé😀"]`, ""},
+		{`[0x1F, -0xa, +1, .5, 5., 1e3, Infinity, -Infinity, NaN]`, `[31,-10,1,0.5,5.0,1e3,"Infinity","-Infinity","NaN"]`, ""},
+		{`{a: [1, [2,],], b: {c: null,},}`, `{"a":[1,[2]],"b":{"c":null}}`, ""},
+		{`{"a": 1} // done`, `{"a":1}`, ""},
+		{`{"a": 1,, }`, "", `expected a key, got ','`},
+		{"{\n\"a\": 1\n\"b\": 2}", "", "line 3: expected , or }"},
+		{`{"a": /* x }`, "", "unterminated comment"},
+		{`{"a": 'x}`, "", "unterminated string"},
+		{`{"a": undefined}`, "", `invalid value "undefined"`},
+		{`{"a": 1} x`, "", "after top-level value"},
+	}
+	for i, test := range tests {
+		v, err := decodeJSON5([]byte(test.json5))
+		if err != nil {
+			if test.err == "" || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%d: got error %q, want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%d: got %s want %s", i, b, test.expected)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// OutputFormat is the format of the generated output.
+type OutputFormat int
+
+const (
+	// GoSource is Go type definitions.  This is the default.
+	GoSource OutputFormat = iota
+	// JSONSchema is a JSON Schema, draft 2020-12, document that describes
+	// the type.
+	JSONSchema
+)
+
+// ParseOutputFormat returns the OutputFormat for s, which is one of go or
+// jsonschema.
+func ParseOutputFormat(s string) (OutputFormat, error) {
+	switch s {
+	case "go":
+		return GoSource, nil
+	case "jsonschema":
+		return JSONSchema, nil
+	}
+	return GoSource, fmt.Errorf("unknown output format %q: expected go or jsonschema", s)
+}
+
+// schemaDraft is the JSON Schema dialect of the generated schemas.
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// orderedObject is a JSON object whose keys are encoded in the order they
+// were set.
+type orderedObject struct {
+	keys []string
+	vals map[string]interface{}
+}
+
+// set sets the key's value; if the key is new, it is after the other keys.
+func (o *orderedObject) set(key string, v interface{}) {
+	if o.vals == nil {
+		o.vals = map[string]interface{}{}
+	}
+	if _, ok := o.vals[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.vals[key] = v
+}
+
+func (o *orderedObject) MarshalJSON() ([]byte, error) {
+	var buff bytes.Buffer
+	buff.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			buff.WriteByte(',')
+		}
+		b, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(b)
+		buff.WriteByte(':')
+		b, err = json.Marshal(o.vals[k])
+		if err != nil {
+			return nil, err
+		}
+		buff.Write(b)
+	}
+	buff.WriteByte('}')
+	return buff.Bytes(), nil
+}
+
+// schemaGen generates a JSON Schema from the types that the Go definitions
+// were generated from, so the type names are those of the Go types.
+type schemaGen struct {
+	t    *Transmogrifier
+	root *jsonType
+	// uses is the number of times each object type is used; objects that
+	// are used more than once are defined in $defs.
+	uses map[*jsonType]int
+	defs orderedObject
+	// defined are the object types that are in, or being added to, defs.
+	defined map[*jsonType]bool
+}
+
+// jsonSchema returns the JSON Schema, draft 2020-12, of the root type.  The
+// Go definitions must have been generated, so that the object types are
+// named.
+func (t *Transmogrifier) jsonSchema(root *jsonType) ([]byte, error) {
+	g := schemaGen{t: t, root: root, uses: map[*jsonType]int{}, defined: map[*jsonType]bool{}}
+	g.countUses(root)
+	s := orderedObject{}
+	s.set("$schema", schemaDraft)
+	s.set("title", root.name)
+	if root.name == "" {
+		s.set("title", t.name)
+	}
+	// a map's values are described by additionalProperties, e.g. for
+	// MapType
+	body := &orderedObject{}
+	if root.mapOf != nil {
+		body.set("type", "object")
+		body.set("additionalProperties", g.schema(root.mapOf))
+	} else {
+		body = g.object(root)
+	}
+	for _, k := range body.keys {
+		s.set(k, body.vals[k])
+	}
+	if len(g.defs.keys) > 0 {
+		s.set("$defs", &g.defs)
+	}
+	b, err := json.MarshalIndent(&s, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
+
+// countUses counts the uses of each object type within t.
+func (g *schemaGen) countUses(t *jsonType) {
+	if t == nil {
+		return
+	}
+	if t.kind == objectKind && t.mapOf == nil {
+		g.uses[t]++
+		if g.uses[t] > 1 {
+			return
+		}
+		for _, f := range t.fields {
+			g.countUses(f.typ)
+		}
+		return
+	}
+	g.countUses(t.elem)
+	g.countUses(t.mapOf)
+}
+
+// schema returns the schema of t.
+func (g *schemaGen) schema(t *jsonType) interface{} {
+	s := &orderedObject{}
+	if t.kind == objectKind && t.mapOf == nil {
+		switch {
+		case t == g.root:
+			s.set("$ref", "#")
+		case g.uses[t] > 1 && t.name != "":
+			if !g.defined[t] {
+				g.defined[t] = true
+				// the definition is added before its fields are,
+				// so that recursive uses refer to it
+				g.defs.set(t.name, nil)
+				g.defs.set(t.name, g.object(t))
+			}
+			s.set("$ref", "#/$defs/"+t.name)
+		default:
+			s = g.object(t)
+		}
+		return g.nullable(t, s)
+	}
+	if t.desc != "" {
+		s.set("description", t.desc)
+	}
+	switch t.kind {
+	case boolKind:
+		s.set("type", "boolean")
+	case intKind:
+		s.set("type", "integer")
+	case floatKind:
+		s.set("type", "number")
+	case stringKind:
+		s.set("type", "string")
+		if t.isDateTime() {
+			s.set("format", "date-time")
+			break
+		}
+		if g.t.EnumThreshold > 0 || t.declared {
+			if vals := t.enumValues(g.t.EnumThreshold); vals != nil {
+				s.set("enum", vals)
+			}
+		}
+	case objectKind:
+		s.set("type", "object")
+		s.set("additionalProperties", g.schema(t.mapOf))
+	case arrayKind:
+		s.set("type", "array")
+		if t.elem != nil {
+			s.set("items", g.schema(t.elem))
+		}
+	}
+	return g.nullable(t, s)
+}
+
+// nullable returns the schema, s, of t allowing null if nulls were observed
+// for t.
+func (g *schemaGen) nullable(t *jsonType, s *orderedObject) interface{} {
+	if t.nulls == 0 || t.kind == nullKind || t.kind == mixedKind {
+		return s
+	}
+	if typ, ok := s.vals["type"].(string); ok {
+		s.set("type", []string{typ, "null"})
+		return s
+	}
+	null := &orderedObject{}
+	null.set("type", "null")
+	alt := &orderedObject{}
+	alt.set("anyOf", []interface{}{s, null})
+	return alt
+}
+
+// object returns the schema of the object t: its properties, in field order,
+// and the keys that were present in every object, or declared as required.
+func (g *schemaGen) object(t *jsonType) *orderedObject {
+	s := &orderedObject{}
+	if t.desc != "" {
+		s.set("description", t.desc)
+	}
+	s.set("type", "object")
+	props := &orderedObject{}
+	var required []string
+	for _, f := range t.orderedFields(g.t.FieldOrder) {
+		props.set(f.key, g.schema(f.typ))
+		if f.count >= t.objects {
+			required = append(required, f.key)
+		}
+	}
+	s.set("properties", props)
+	if len(required) > 0 {
+		s.set("required", required)
+	}
+	return s
+}
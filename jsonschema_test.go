@@ -0,0 +1,152 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected string
+	}{
+		{
+			`[{"id": 1, "at": "2020-01-02T03:04:05Z", "kind": "a", "note": null}, {"id": 2.5, "at": "2021-01-02T03:04:05.5+01:00", "kind": "b", "note": "n", "tags": ["t"]}]`,
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Test", "type": "object",
+				"properties": {
+					"at": {"type": "string", "format": "date-time"},
+					"id": {"type": "number"},
+					"kind": {"type": "string", "enum": ["a", "b"]},
+					"note": {"type": ["string", "null"]},
+					"tags": {"type": "array", "items": {"type": "string"}}
+				},
+				"required": ["at", "id", "kind", "note"]}`,
+		},
+		{
+			`{"topic": "towels", "root": {"id": 1, "replies": [{"id": 2, "replies": []}]}, "parent": {"topic": "hitchhiking", "parent": null}}`,
+			`{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Test", "type": "object",
+				"properties": {
+					"parent": {"anyOf": [{"$ref": "#"}, {"type": "null"}]},
+					"root": {"$ref": "#/$defs/Root"},
+					"topic": {"type": "string", "enum": ["hitchhiking", "towels"]}
+				},
+				"required": ["parent", "topic"],
+				"$defs": {
+					"Root": {"type": "object",
+						"properties": {
+							"id": {"type": "integer"},
+							"replies": {"type": "array", "items": {"$ref": "#/$defs/Root"}}
+						},
+						"required": ["id", "replies"]}
+				}}`,
+		},
+	}
+	for i, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("test", bytes.NewReader([]byte(test.json)), &buff)
+		calvin.Format = JSONSchema
+		calvin.EnumThreshold = 2
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		var got, want interface{}
+		err = json.Unmarshal(buff.Bytes(), &got)
+		if err != nil {
+			t.Errorf("%d: invalid JSON: %s: %s", i, err, buff.String())
+			continue
+		}
+		err = json.Unmarshal([]byte(test.expected), &want)
+		if err != nil {
+			t.Errorf("%d: invalid expected JSON: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%d: got %s", i, buff.String())
+		}
+	}
+}
+
+func TestJSONSchemaRoundTrip(t *testing.T) {
+	// a schema that is generated from a schema declares the same types
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("order", bytes.NewReader(schema), &buff)
+	calvin.From = Schema
+	calvin.Format = JSONSchema
+	err := calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var src, gen bytes.Buffer
+	calvin = NewTransmogrifier("order", bytes.NewReader(schema), &src)
+	calvin.From = Schema
+	err = calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	calvin = NewTransmogrifier("order", &buff, &gen)
+	calvin.From = Schema
+	err = calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if src.String() != gen.String() {
+		t.Errorf("got %q want %q", gen.String(), src.String())
+	}
+}
+
+func TestParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected OutputFormat
+		err      bool
+	}{
+		{"go", GoSource, false},
+		{"jsonschema", JSONSchema, false},
+		{"yaml", GoSource, true},
+	}
+	for _, test := range tests {
+		f, err := ParseOutputFormat(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if f != test.expected {
+			t.Errorf("%s: got %d want %d", test.s, f, test.expected)
+		}
+	}
+}
+
+func TestJSONSchemaMap(t *testing.T) {
+	expected := `{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "Test", "type": "object",
+		"additionalProperties": {"type": "object",
+			"properties": {
+				"id": {"type": "integer"},
+				"name": {"type": "string"}
+			},
+			"required": ["id"]}}`
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", strings.NewReader(`{"a": {"id": 1, "name": "x"}, "b": {"id": 2}}`), &buff)
+	calvin.Format = JSONSchema
+	calvin.MapType = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var got, want interface{}
+	err = json.Unmarshal(buff.Bytes(), &got)
+	if err != nil {
+		t.Fatalf("invalid JSON: %s: %s", err, buff.String())
+	}
+	err = json.Unmarshal([]byte(expected), &want)
+	if err != nil {
+		t.Fatalf("invalid expected JSON: %s", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %s", buff.String())
+	}
+}
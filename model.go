@@ -0,0 +1,118 @@
+package json2go
+
+import (
+	"bytes"
+	"go/format"
+	"strings"
+	"text/template"
+)
+
+// Model is the data that an output template is executed with: the package
+// and the definitions of the types inferred from the JSON.
+type Model struct {
+	// Name is the name of the type that was defined from the JSON.
+	Name string
+	// Package is the name of the package.
+	Package string
+	// Imports are the packages that the type definitions need, sorted.
+	Imports []string
+	// Types are the type definitions in the order that they are output.
+	Types []TypeDef
+}
+
+// TypeDef is a type definition.
+type TypeDef struct {
+	// Name is the name of the type.
+	Name string
+	// Kind is either struct, map, slice, scalar, enum, or alias.
+	Kind string
+	// Type is the underlying type of map, slice, scalar, and enum kinds,
+	// e.g. map[string][]Struct, []User, int, or string, or the aliased
+	// type of an alias, e.g. Page[User].
+	Type string
+	// TypeParams is the type parameter list of a generic struct, without
+	// the brackets, e.g. T any; it is empty if the struct isn't generic.
+	TypeParams string
+	// Fields are the fields of a struct.
+	Fields []FieldDef
+	// Values are the values of an enum.
+	Values []EnumValue
+	// Source is the Go source of the type's definition, along with any of
+	// its methods, as json2go generates it.
+	Source string
+	// children are the names of the structs that were defined for, or
+	// are referred to by, a struct's fields, in field order, or the types
+	// an alias, or slice, refers to.
+	children []string
+	// imports are the packages that the type's source uses.
+	imports []string
+}
+
+// FieldDef is a field within a struct definition.
+type FieldDef struct {
+	TagField
+	// Embedded is true if the field is an embedded struct.
+	Embedded bool
+	// Tag is the field's struct tag, including the enclosing backquotes.
+	Tag string
+	// Doc is the field's comment, without the comment markers; it may be
+	// more than one line.  It is empty unless comments were requested.
+	Doc string
+}
+
+// EnumValue is a value of an enum type and the name of its const.
+type EnumValue struct {
+	Name  string
+	Value string
+}
+
+// defaultTemplate is the output used when a template hasn't been set.
+const defaultTemplate = `package {{.Package}}
+{{if .Imports}}
+import (
+{{range .Imports}}	{{printf "%q" .}}
+{{end}})
+{{end}}{{range .Types}}
+{{.Source}}
+{{end}}`
+
+// SetTemplate sets the text/template used to generate the output; it is
+// executed with the Model of what was inferred from the JSON.  This allows
+// for license headers, build tags, go:generate lines, additional methods,
+// or output other than Go.  The tag formats, e.g. snake, are available as
+// functions.  Unless SkipFormat is set, the output is formatted with gofmt,
+// so it must be valid Go.
+func (t *Transmogrifier) SetTemplate(text string) error {
+	tmpl, err := template.New("json2go").Funcs(tagFuncs).Parse(text)
+	if err != nil {
+		return err
+	}
+	t.template = tmpl
+	return nil
+}
+
+// render executes the output template with the model.
+func (t *Transmogrifier) render(m Model) ([]byte, error) {
+	tmpl := t.template
+	if tmpl == nil {
+		tmpl = template.Must(template.New("json2go").Parse(defaultTemplate))
+	}
+	var buff bytes.Buffer
+	err := tmpl.Execute(&buff, m)
+	if err != nil {
+		return nil, err
+	}
+	if t.SkipFormat {
+		return buff.Bytes(), nil
+	}
+	return format.Source(buff.Bytes())
+}
+
+// formatSource returns the gofmt'd source, without trailing newlines.  If it
+// can't be formatted, it is returned as is.
+func formatSource(src []byte) string {
+	if fmtd, err := format.Source(src); err == nil {
+		src = fmtd
+	}
+	return strings.TrimRight(string(src), "\n")
+}
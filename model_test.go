@@ -0,0 +1,62 @@
+package json2go
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetTemplate(t *testing.T) {
+	tmpl := `// Code generated by json2go. DO NOT EDIT.
+
+//go:build !skip
+
+package {{.Package}}
+{{range .Types}}
+{{.Source}}
+{{if eq .Kind "struct"}}
+// Keys returns the JSON keys of {{.Name}}.
+func ({{.Name}}) Keys() []string {
+	return []string{ {{range .Fields}}{{printf "%q" .Key}},{{end}} }
+}
+{{end}}{{end}}`
+	expected := "// Code generated by json2go. DO NOT EDIT.\n\n//go:build !skip\n\npackage main\n\ntype Test struct {\n\tID     int `json:\"id\"`\n\tWindow `json:\"window\"`\n}\n\n// Keys returns the JSON keys of Test.\nfunc (Test) Keys() []string {\n\treturn []string{\"id\", \"window\"}\n}\n\ntype Window struct {\n\tTitle string `json:\"title\"`\n}\n\n// Keys returns the JSON keys of Window.\nfunc (Window) Keys() []string {\n\treturn []string{\"title\"}\n}\n"
+	r := bytes.NewReader([]byte(`{"id": 1, "window": {"title": "main"}}`))
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	err := calvin.SetTemplate(tmpl)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+func TestSetTemplateSkipFormat(t *testing.T) {
+	tmpl := `{{range .Types}}{{.Name}}:{{range .Fields}} {{snake .Name}}={{.GoType}}{{end}}
+{{end}}`
+	expected := "Test: h_offset=int status=Status\nStatus:\n"
+	r := bytes.NewReader([]byte(`[{"hOffset": 1, "status": "on"}, {"hOffset": 2, "status": "on"}]`))
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	calvin.EnumThreshold = 2
+	calvin.SkipFormat = true
+	err := calvin.SetTemplate(tmpl)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+	if err := calvin.SetTemplate("{{.Package"); err == nil {
+		t.Error("expected an error, got none")
+	}
+}
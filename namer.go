@@ -0,0 +1,235 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Namer creates Go identifiers from JSON keys.  A Namer can be set on a
+// Transmogrifier, using SetNamer, to apply naming conventions other than
+// the default ones.
+type Namer interface {
+	// FieldName returns the name of the struct field for the key.
+	FieldName(key string) string
+	// TypeName returns the name of a type that is derived from the key,
+	// e.g. the struct definition for an object or the element type of an
+	// array.  For the element type of an array, the key will already be
+	// singularized.
+	TypeName(key string) string
+}
+
+// Initialisms is a set of initialisms, e.g. ID or URL, that are uppercased
+// when they, or their plural, are a word within a name, e.g. userId becomes
+// UserID and userIds becomes UserIDs.  The members of the set are
+// uppercase.  A nil Initialisms is the set of common initialisms.
+type Initialisms map[string]struct{}
+
+// NewInitialisms returns a set containing the common initialisms, which can
+// then be added to or removed from.
+func NewInitialisms() Initialisms {
+	in := make(Initialisms, len(commonInitialisms))
+	for k := range commonInitialisms {
+		in[k] = struct{}{}
+	}
+	return in
+}
+
+// Add adds the initialisms to the set.
+func (in Initialisms) Add(v ...string) {
+	for _, s := range v {
+		in[strings.ToUpper(s)] = struct{}{}
+	}
+}
+
+// Remove removes the initialisms from the set.
+func (in Initialisms) Remove(v ...string) {
+	for _, s := range v {
+		delete(in, strings.ToUpper(s))
+	}
+}
+
+// upper returns s uppercased if it is an initialism, or s with all but its
+// trailing s uppercased if it is the plural of one.  Otherwise s is returned
+// as is.
+func (in Initialisms) upper(s string) string {
+	if in == nil {
+		in = commonInitialisms
+	}
+	tmp := strings.ToUpper(s)
+	if _, ok := in[tmp]; ok {
+		return tmp
+	}
+	if len(s) > 2 && s[len(s)-1] == 's' {
+		if _, ok := in[tmp[:len(tmp)-1]]; ok {
+			return tmp[:len(tmp)-1] + "s"
+		}
+	}
+	return s
+}
+
+// UnderscoreNamer only treats underscores as word separators: underscores
+// are removed and each word has its first rune uppercased.  Any other runes
+// are left as is, except for discardable runes at the start of the key.
+type UnderscoreNamer struct {
+	// Initialisms are the initialisms to uppercase.  If nil, either the
+	// Transmogrifier's initialisms or the common initialisms are used.
+	Initialisms Initialisms
+}
+
+// FieldName returns the field name for the key.
+func (n UnderscoreNamer) FieldName(key string) string {
+	var name string
+	vals := strings.Split(key, "_")
+	for i, v := range vals {
+		if i == 0 {
+			name = cleanFieldName(v)
+			name = n.Initialisms.upper(name)
+			continue
+		}
+		name = fmt.Sprintf("%s%s", name, n.Initialisms.upper(strings.Title(v)))
+	}
+	return name
+}
+
+// TypeName returns the type name for the key.
+func (n UnderscoreNamer) TypeName(key string) string {
+	return n.FieldName(key)
+}
+
+// WordNamer splits keys into words on underscores, dashes, dots, spaces, and
+// any other rune that isn't a letter or a digit, along with camelCase
+// boundaries, e.g. user_name, user-name, user.name, "user name", and
+// userName all result in UserName.  Each word has its first rune
+// uppercased, unless it is an initialism, in which case it is uppercased.
+// A leading digit is converted to its word equivalent.  This is the default
+// Namer.
+type WordNamer struct {
+	// Initialisms are the initialisms to uppercase.  If nil, either the
+	// Transmogrifier's initialisms or the common initialisms are used.
+	Initialisms Initialisms
+}
+
+// FieldName returns the field name for the key.
+func (n WordNamer) FieldName(key string) string {
+	var name string
+	for i, w := range splitWords(key) {
+		if i == 0 {
+			r, width := utf8.DecodeRuneInString(w)
+			if s := numToAlpha(r); s != "" {
+				w = s + w[width:]
+			}
+		}
+		name += n.Initialisms.upper(upperFirst(w))
+	}
+	return name
+}
+
+// TypeName returns the type name for the key.
+func (n WordNamer) TypeName(key string) string {
+	return n.FieldName(key)
+}
+
+// splitWords splits s into words.  Any rune that isn't a letter or a digit
+// separates words, as does a change from a lowercase letter, or digit, to
+// an uppercase one.  Within a run of uppercase letters, the last one starts
+// a new word if it is followed by a lowercase letter, e.g. HTTPServer is
+// HTTP and Server, unless that is just a trailing s, e.g. IDs.
+func splitWords(s string) []string {
+	var words []string
+	rs := []rune(s)
+	start := -1
+	for i, r := range rs {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start >= 0 {
+				words = append(words, string(rs[start:i]))
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		if !unicode.IsUpper(r) {
+			continue
+		}
+		prev := rs[i-1]
+		if unicode.IsLower(prev) || unicode.IsDigit(prev) {
+			words = append(words, string(rs[start:i]))
+			start = i
+			continue
+		}
+		// r is within a run of uppercase letters
+		if i+1 < len(rs) && unicode.IsLower(rs[i+1]) && !isPluralS(rs, i+1) {
+			words = append(words, string(rs[start:i]))
+			start = i
+		}
+	}
+	if start >= 0 {
+		words = append(words, string(rs[start:]))
+	}
+	return words
+}
+
+// isPluralS returns whether the rune at i is an s that ends a word.
+func isPluralS(rs []rune, i int) bool {
+	if rs[i] != 's' {
+		return false
+	}
+	return i+1 == len(rs) || !unicode.IsLower(rs[i+1])
+}
+
+// upperFirst returns s with its first rune uppercased.
+func upperFirst(s string) string {
+	r, n := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[n:]
+}
+
+// SetNamer sets the Namer used to create the field and type names.  If n is
+// nil, the default Namer, WordNamer, is used.
+func (t *Transmogrifier) SetNamer(n Namer) {
+	t.namer = n
+}
+
+// getNamer returns the Namer to use.  The built-in Namers use the
+// Transmogrifier's initialisms, unless their Initialisms has been set.
+func (t *Transmogrifier) getNamer() Namer {
+	switch n := t.namer.(type) {
+	case nil:
+		return WordNamer{Initialisms: t.initialisms}
+	case WordNamer:
+		if n.Initialisms == nil {
+			n.Initialisms = t.initialisms
+		}
+		return n
+	case UnderscoreNamer:
+		if n.Initialisms == nil {
+			n.Initialisms = t.initialisms
+		}
+		return n
+	}
+	return t.namer
+}
+
+// AddInitialisms adds initialisms, e.g. ETA or SKU, to the ones that are
+// uppercased when they are a word in a name.
+func (t *Transmogrifier) AddInitialisms(v ...string) {
+	if t.initialisms == nil {
+		t.initialisms = NewInitialisms()
+	}
+	t.initialisms.Add(v...)
+}
+
+// RemoveInitialisms removes initialisms from the ones that are uppercased
+// when they are a word in a name.
+func (t *Transmogrifier) RemoveInitialisms(v ...string) {
+	if t.initialisms == nil {
+		t.initialisms = NewInitialisms()
+	}
+	t.initialisms.Remove(v...)
+}
@@ -0,0 +1,126 @@
+package json2go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected []string
+	}{
+		{"foo", []string{"foo"}},
+		{"foo_bar", []string{"foo", "bar"}},
+		{"foo-bar", []string{"foo", "bar"}},
+		{"foo.bar", []string{"foo", "bar"}},
+		{"foo bar", []string{"foo", "bar"}},
+		{"fooBar", []string{"foo", "Bar"}},
+		{"hOffset", []string{"h", "Offset"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"userIDs", []string{"user", "IDs"}},
+		{"FCTTIME", []string{"FCTTIME"}},
+		{"v2Api", []string{"v2", "Api"}},
+		{"__foo__", []string{"foo"}},
+		{"", nil},
+		{"日本語a", []string{"日本語a"}},
+	}
+	for i, test := range tests {
+		words := splitWords(test.key)
+		if strings.Join(words, "|") != strings.Join(test.expected, "|") {
+			t.Errorf("%d: splitWords(%q): got %q, want %q", i, test.key, words, test.expected)
+		}
+	}
+}
+
+func TestNamers(t *testing.T) {
+	tests := []struct {
+		key        string
+		underscore string
+		word       string
+	}{
+		{"foo_bar", "FooBar", "FooBar"},
+		{"biz_id", "BizID", "BizID"},
+		{"hOffset", "HOffset", "HOffset"},
+		{"foo-bar", "Foo-bar", "FooBar"},
+		{"foo.bar", "Foo.bar", "FooBar"},
+		{"first name", "First name", "FirstName"},
+		{"6dsaf", "Sixdsaf", "Sixdsaf"},
+		{"_asdf", "Asdf", "Asdf"},
+		{"userId", "UserId", "UserID"},
+		{"user_ids", "UserIDs", "UserIDs"},
+		{"apiUrl", "ApiUrl", "APIURL"},
+		{"httpStatus", "HttpStatus", "HTTPStatus"},
+		{"HTTPStatus", "HTTPStatus", "HTTPStatus"},
+	}
+	for i, test := range tests {
+		if s := (UnderscoreNamer{}).FieldName(test.key); s != test.underscore {
+			t.Errorf("%d: UnderscoreNamer: got %q, want %q", i, s, test.underscore)
+		}
+		if s := (WordNamer{}).FieldName(test.key); s != test.word {
+			t.Errorf("%d: WordNamer: got %q, want %q", i, s, test.word)
+		}
+	}
+}
+
+// prefixNamer is a Namer that prefixes type names.
+type prefixNamer struct {
+	WordNamer
+}
+
+func (n prefixNamer) TypeName(key string) string {
+	return "Acme" + n.WordNamer.TypeName(key)
+}
+
+func TestSetNamer(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\tItems  []AcmeItem `json:\"items\"`\n\tWindow AcmeWindow `json:\"window\"`\n}\n\ntype AcmeItem struct {\n\tItemID int `json:\"item-id\"`\n}\n\ntype AcmeWindow struct {\n\tTitle string `json:\"title\"`\n}\n"
+	r := bytes.NewReader([]byte(`{"items": [{"item-id": 1}], "window": {"title": "main"}}`))
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	calvin.SetNamer(prefixNamer{})
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+func TestInitialisms(t *testing.T) {
+	tests := []struct {
+		add      []string
+		remove   []string
+		key      string
+		expected string
+	}{
+		{nil, nil, "sku_eta", "SkuEta"},
+		{[]string{"sku", "ETA"}, nil, "sku_eta", "SKUETA"},
+		{[]string{"sku"}, nil, "skuIds", "SKUIDs"},
+		{nil, []string{"id"}, "userId", "UserId"},
+		{nil, []string{"ID"}, "user_url", "UserURL"},
+	}
+	for i, test := range tests {
+		calvin := NewTransmogrifier("test", nil, nil)
+		calvin.AddInitialisms(test.add...)
+		calvin.RemoveInitialisms(test.remove...)
+		for _, n := range []Namer{nil, WordNamer{}} {
+			calvin.SetNamer(n)
+			if s := calvin.getNamer().FieldName(test.key); s != test.expected {
+				t.Errorf("%d: got %q, want %q", i, s, test.expected)
+			}
+		}
+	}
+	// an explicitly set Initialisms takes precedence
+	calvin := NewTransmogrifier("test", nil, nil)
+	calvin.AddInitialisms("sku")
+	calvin.SetNamer(WordNamer{Initialisms: NewInitialisms()})
+	if s := calvin.getNamer().FieldName("sku_id"); s != "SkuID" {
+		t.Errorf("got %q, want %q", s, "SkuID")
+	}
+	// the common initialisms aren't affected
+	if _, ok := commonInitialisms["SKU"]; ok {
+		t.Error("expected the common initialisms to be unchanged")
+	}
+}
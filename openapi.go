@@ -0,0 +1,181 @@
+package json2go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// openAPIRoot is a type that is generated from an OpenAPI document.
+type openAPIRoot struct {
+	// name is what the type is named from, e.g. the component's name.
+	name string
+	typ  *jsonType
+	// body is true if the type is an operation's request or response
+	// body.
+	body bool
+}
+
+// operationMethods are the operations of an OpenAPI path item, in the order
+// that their bodies are generated.
+var operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// SetComponents sets the names of the schemas, in the OpenAPI document's
+// components, that types are generated for.  If none are set, types are
+// generated for all of them.
+func (t *Transmogrifier) SetComponents(names ...string) {
+	t.components = names
+}
+
+// openAPIRoots returns the types to generate from the decoded OpenAPI 3
+// document, with its key order: the component schemas and, if Operations is
+// set, the request and response bodies of each operation that has an
+// operationId.  Only the components that are objects, and string enums, are
+// generated; other types are only used by the fields that refer to them.
+func (t *Transmogrifier) openAPIRoots(doc interface{}, order *keyOrder) ([]openAPIRoot, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid OpenAPI document: expected an object")
+	}
+	if v, _ := m["openapi"].(string); !strings.HasPrefix(v, "3.") {
+		return nil, fmt.Errorf("invalid OpenAPI document: expected openapi to be a 3.x version")
+	}
+	c := schemaConv{root: doc, order: order, refs: map[string]*jsonType{}}
+	var roots []openAPIRoot
+	names := t.components
+	if len(names) == 0 {
+		schemas, _ := m["components"].(map[string]interface{})
+		schemas, _ = schemas["schemas"].(map[string]interface{})
+		names = sortedKeys(schemas)
+		if o := order.child("components").child("schemas"); o != nil {
+			names = o.keys
+		}
+	}
+	for _, name := range names {
+		typ, err := c.resolve("#/components/schemas/" + escapePointer(name))
+		if err != nil {
+			return nil, fmt.Errorf("component %q: %s", name, err)
+		}
+		roots = append(roots, openAPIRoot{name: name, typ: typ})
+	}
+	if !t.Operations {
+		return roots, nil
+	}
+	paths, _ := m["paths"].(map[string]interface{})
+	pathNames := sortedKeys(paths)
+	if o := order.child("paths"); o != nil {
+		pathNames = o.keys
+	}
+	for _, path := range pathNames {
+		item, _ := paths[path].(map[string]interface{})
+		for _, method := range operationMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			id, _ := op["operationId"].(string)
+			if id == "" {
+				continue
+			}
+			ref := "#/paths/" + escapePointer(path) + "/" + method
+			typ, err := c.bodyType(op["requestBody"], ref+"/requestBody")
+			if err != nil {
+				return nil, fmt.Errorf("operation %q request: %s", id, err)
+			}
+			if typ != nil {
+				roots = append(roots, openAPIRoot{name: id + "Request", typ: typ, body: true})
+			}
+			code := successResponse(op["responses"])
+			if code == "" {
+				continue
+			}
+			responses, _ := op["responses"].(map[string]interface{})
+			typ, err = c.bodyType(responses[code], ref+"/responses/"+escapePointer(code))
+			if err != nil {
+				return nil, fmt.Errorf("operation %q response: %s", id, err)
+			}
+			if typ != nil {
+				roots = append(roots, openAPIRoot{name: id + "Response", typ: typ, body: true})
+			}
+		}
+	}
+	return roots, nil
+}
+
+// bodyType returns the type of the JSON content of the request body, or
+// response, body, which is at ref and may be a reference to one in the
+// components.  Nil is returned if there isn't a body, i.e. body is nil, or it
+// doesn't have any JSON content.
+func (c *schemaConv) bodyType(body interface{}, ref string) (*jsonType, error) {
+	if body == nil {
+		return nil, nil
+	}
+	// seen are the refs that have been followed, so that a reference
+	// cycle is an error rather than an endless loop
+	seen := map[string]bool{}
+	for {
+		m, _ := body.(map[string]interface{})
+		r, ok := m["$ref"].(string)
+		if !ok {
+			break
+		}
+		if seen[r] {
+			return nil, fmt.Errorf("invalid $ref %q: circular reference", r)
+		}
+		seen[r] = true
+		ref = r
+		var err error
+		body, _, _, err = c.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+	m, _ := body.(map[string]interface{})
+	content, _ := m["content"].(map[string]interface{})
+	var media string
+	for _, k := range sortedKeys(content) {
+		if k == "application/json" {
+			media = k
+			break
+		}
+		if media == "" && strings.HasSuffix(strings.SplitN(k, ";", 2)[0], "json") {
+			media = k
+		}
+	}
+	if media == "" {
+		return nil, nil
+	}
+	mt, _ := content[media].(map[string]interface{})
+	schema, ok := mt["schema"]
+	if !ok {
+		return nil, nil
+	}
+	return c.typeOf(schema, c.orderOf(ref+"/content/"+escapePointer(media)+"/schema"))
+}
+
+// orderOf returns the key order of the value that ref refers to, if any.
+func (c *schemaConv) orderOf(ref string) *keyOrder {
+	_, o, _, _ := c.lookup(ref)
+	return o
+}
+
+// successResponse returns the status code of the operation's successful
+// response: the lowest 2xx code, 2XX, or default.  An empty string is
+// returned if there isn't one.
+func successResponse(responses interface{}) string {
+	m, _ := responses.(map[string]interface{})
+	codes := sortedKeys(m)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			return code
+		}
+	}
+	if _, ok := m["default"]; ok {
+		return "default"
+	}
+	return ""
+}
+
+// escapePointer escapes s for use as a JSON Pointer token.
+func escapePointer(s string) string {
+	return strings.Replace(strings.Replace(s, "~", "~0", -1), "/", "~1", -1)
+}
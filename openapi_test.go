@@ -0,0 +1,197 @@
+package json2go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var openAPIDoc = []byte(`{
+	"openapi": "3.0.3",
+	"info": {"title": "Pets", "version": "1.0.0"},
+	"paths": {
+		"/pets": {
+			"get": {
+				"operationId": "listPets",
+				"responses": {
+					"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "object", "properties": {"pets": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}, "next": {"type": "string", "nullable": true}}}}}}
+				}
+			},
+			"post": {
+				"operationId": "createPet",
+				"requestBody": {"$ref": "#/components/requestBodies/NewPet"},
+				"responses": {
+					"201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Pet"}}}},
+					"default": {"description": "error", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+				}
+			}
+		},
+		"/pets/search": {
+			"get": {
+				"operationId": "searchPets",
+				"responses": {
+					"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}
+				}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Pet": {"type": "object", "required": ["id", "name"], "properties": {"id": {"type": "integer", "format": "int64"}, "name": {"type": "string"}, "status": {"$ref": "#/components/schemas/Status"}, "owner": {"$ref": "#/components/schemas/Owner"}}},
+			"Owner": {"type": "object", "properties": {"name": {"type": "string"}, "pets": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}},
+			"Status": {"type": "string", "enum": ["available", "sold"]},
+			"Error": {"type": "object", "properties": {"message": {"type": "string"}}}
+		},
+		"requestBodies": {
+			"NewPet": {"content": {"application/json; charset=utf-8": {"schema": {"type": "object", "properties": {"name": {"type": "string"}}}}}}
+		}
+	}
+}`)
+
+func TestOpenAPI(t *testing.T) {
+	tests := []struct {
+		components []string
+		operations bool
+		expected   []string
+	}{
+		{nil, false, []string{"Pet", "Owner", "Error", "Status"}},
+		{[]string{"Error"}, false, []string{"Error"}},
+		{nil, true, []string{"CreatePetResponse", "SearchPetsResponse", "Pet", "Owner", "Error", "ListPetsResponse", "CreatePetRequest", "Status"}},
+	}
+	for i, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("pets", bytes.NewReader(openAPIDoc), &buff)
+		calvin.From = OpenAPI
+		calvin.Operations = test.operations
+		calvin.SetComponents(test.components...)
+		err := calvin.SetTemplate("{{range .Types}}{{.Name}}\n{{end}}")
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		calvin.SkipFormat = true
+		err = calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		names := strings.Fields(buff.String())
+		if strings.Join(names, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("%d: got %q want %q", i, names, test.expected)
+		}
+	}
+}
+
+func TestOpenAPISource(t *testing.T) {
+	expected := "package main\n\ntype Pet struct {\n\tID     int    `json:\"id\"`\n\tName   string `json:\"name\"`\n\tStatus Status `json:\"status\"`\n\tOwner  Owner  `json:\"owner\"`\n}\n\ntype Status string\n\nconst (\n\tStatusAvailable Status = \"available\"\n\tStatusSold      Status = \"sold\"\n)\n\n// IsValid reports whether v is a known Status value.\nfunc (v Status) IsValid() bool {\n\tswitch v {\n\tcase StatusAvailable, StatusSold:\n\t\treturn true\n\t}\n\treturn false\n}\n\ntype Owner struct {\n\tName string `json:\"name\"`\n\tPets []Pet  `json:\"pets\"`\n}\n"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("pets", bytes.NewReader(openAPIDoc), &buff)
+	calvin.From = OpenAPI
+	calvin.FieldOrder = SourceOrder
+	calvin.SetComponents("Pet", "Owner")
+	calvin.Verify = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+func TestOpenAPIErrors(t *testing.T) {
+	tests := []struct {
+		doc        string
+		components []string
+		err        string
+	}{
+		{`{"swagger": "2.0"}`, nil, "expected openapi to be a 3.x version"},
+		{`{"openapi": "3.1.0", "components": {"schemas": {"A": {"type": "object"}}}}`, []string{"B"}, `component "B"`},
+		{`{"openapi": "3.1.0", "components": {"schemas": {"A": {"type": "string"}}}}`, nil, "doesn't have any object schemas"},
+		{`{"openapi": "3.1.0", "paths": {"/a": {"post": {"operationId": "a", "requestBody": {"$ref": "#/components/requestBodies/Missing"}}}}}`, nil, `operation "a" request: invalid $ref "#/components/requestBodies/Missing": not found`},
+		{`{"openapi": "3.1.0", "paths": {"/a": {"post": {"operationId": "a", "requestBody": {"$ref": "#/components/requestBodies/A"}}}}, "components": {"requestBodies": {"A": {"$ref": "#/components/requestBodies/A"}}}}`, nil, `operation "a" request: invalid $ref "#/components/requestBodies/A": circular reference`},
+		{`{"openapi": "3.1.0", "paths": {"/a": {"get": {"operationId": "a", "responses": {"200": {"$ref": "#/components/responses/A"}}}}}, "components": {"responses": {"A": {"$ref": "#/components/responses/B"}, "B": {"$ref": "#/components/responses/A"}}}}`, nil, `operation "a" response: invalid $ref "#/components/responses/A": circular reference`},
+	}
+	for i, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("api", strings.NewReader(test.doc), &buff)
+		calvin.From = OpenAPI
+		calvin.Operations = true
+		calvin.SetComponents(test.components...)
+		err := calvin.Gen()
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%d: got error %v want %q", i, err, test.err)
+		}
+	}
+}
+
+func TestOpenAPIOperations(t *testing.T) {
+	expected := "package main\n\ntype CreatePetResponse = Pet\n\ntype SearchPetsResponse []Pet\n\ntype Pet struct {"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("pets", bytes.NewReader(openAPIDoc), &buff)
+	calvin.From = OpenAPI
+	calvin.Operations = true
+	calvin.Verify = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.HasPrefix(buff.String(), expected) {
+		t.Errorf("got %q want it to start with %q", buff.String(), expected)
+	}
+}
+
+func TestOpenAPITypeOrder(t *testing.T) {
+	doc := []byte(`{
+	"openapi": "3.0.3",
+	"info": {"title": "Pets", "version": "1.0.0"},
+	"paths": {
+		"/pets": {
+			"get": {
+				"operationId": "listPets",
+				"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}}}}
+			},
+			"post": {
+				"operationId": "createPet",
+				"responses": {"201": {"description": "created", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Status"}}}}}
+			}
+		}
+	},
+	"components": {
+		"schemas": {
+			"Tag": {"type": "object", "properties": {"name": {"type": "string"}}},
+			"Pet": {"type": "object", "properties": {"owner": {"$ref": "#/components/schemas/Owner"}, "status": {"$ref": "#/components/schemas/Status"}, "tag": {"$ref": "#/components/schemas/Tag"}}},
+			"Owner": {"type": "object", "properties": {"pets": {"type": "array", "items": {"$ref": "#/components/schemas/Pet"}}}},
+			"Status": {"type": "string", "enum": ["available", "sold"]}
+		}
+	}
+}`)
+	tests := []struct {
+		order    TypeOrder
+		expected []string
+	}{
+		{DepthFirst, []string{"ListPetsResponse", "Pet", "Owner", "Status", "Tag", "CreatePetResponse"}},
+		{ByName, []string{"ListPetsResponse", "CreatePetResponse", "Owner", "Pet", "Status", "Tag"}},
+	}
+	for _, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("pets", bytes.NewReader(doc), &buff)
+		calvin.From = OpenAPI
+		calvin.Operations = true
+		calvin.TypeOrder = test.order
+		err := calvin.SetTemplate("{{range .Types}}{{.Name}}\n{{end}}")
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %s", test.order, err)
+		}
+		calvin.SkipFormat = true
+		err = calvin.Gen()
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", test.order, err)
+			continue
+		}
+		names := strings.Fields(buff.String())
+		if strings.Join(names, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("%d: got %q want %q", test.order, names, test.expected)
+		}
+	}
+}
@@ -0,0 +1,343 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// FieldOrder is the order of the fields within a struct definition.
+type FieldOrder int
+
+const (
+	// Alphabetical orders fields by their JSON key.  This is the default.
+	Alphabetical FieldOrder = iota
+	// SourceOrder orders fields in the order that their keys appear in
+	// the source JSON.  Keys that only appear in later samples are placed
+	// after the key that precedes them in that sample.
+	SourceOrder
+	// RequiredFirst orders fields that were present in every sample
+	// before those that weren't; within each group, fields are in source
+	// order.
+	RequiredFirst
+)
+
+// ParseFieldOrder returns the FieldOrder for s, which is one of alpha,
+// source, or required.
+func ParseFieldOrder(s string) (FieldOrder, error) {
+	switch s {
+	case "alpha", "alphabetical":
+		return Alphabetical, nil
+	case "source":
+		return SourceOrder, nil
+	case "required":
+		return RequiredFirst, nil
+	}
+	return Alphabetical, fmt.Errorf("unknown field order %q: expected alpha, source, or required", s)
+}
+
+// keyOrder is the order of the keys of a decoded JSON value.  For an object,
+// keys are its keys, in source order, and children holds the keyOrder of
+// each of its values.  For an array, elems holds the keyOrder of each
+// element.  A nil keyOrder is valid: nothing about the order is known.
+//
+// For a number, num is how it was written, e.g. 72.0, which json.Unmarshal
+// doesn't distinguish from 72, or a large integer, which it rounds.
+type keyOrder struct {
+	keys     []string
+	children map[string]*keyOrder
+	elems    []*keyOrder
+	num      json.Number
+}
+
+// isFloat returns whether the number was written as a float, e.g. 72.0.
+func (o *keyOrder) isFloat() bool {
+	return o != nil && strings.ContainsAny(o.num.String(), ".eE")
+}
+
+// child returns the keyOrder of the object's value for key.
+func (o *keyOrder) child(key string) *keyOrder {
+	if o == nil {
+		return nil
+	}
+	return o.children[key]
+}
+
+// elem returns the keyOrder of the array's i-th element.
+func (o *keyOrder) elem(i int) *keyOrder {
+	if o == nil || i >= len(o.elems) {
+		return nil
+	}
+	return o.elems[i]
+}
+
+// decodeOrdered decodes the JSON-encoded data the same way json.Unmarshal
+// does into an interface{}, along with the order of the keys of every object
+// within it.
+func decodeOrdered(data []byte) (interface{}, *keyOrder, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	v, o, err := decodeValue(dec)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, nil, fmt.Errorf("invalid JSON: unexpected data after top-level value")
+	}
+	return v, o, nil
+}
+
+// decodeValue decodes the next value from dec.
+func decodeValue(dec *json.Decoder) (interface{}, *keyOrder, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		m := map[string]interface{}{}
+		o := &keyOrder{children: map[string]*keyOrder{}}
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, nil, err
+			}
+			key := tok.(string)
+			v, child, err := decodeValue(dec)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := m[key]; !ok {
+				o.keys = append(o.keys, key)
+			}
+			m[key] = v
+			o.children[key] = child
+		}
+		// consume the closing delim
+		if _, err := dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		return m, o, nil
+	case json.Delim('['):
+		a := []interface{}{}
+		o := &keyOrder{}
+		for dec.More() {
+			v, child, err := decodeValue(dec)
+			if err != nil {
+				return nil, nil, err
+			}
+			a = append(a, v)
+			o.elems = append(o.elems, child)
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil, nil, err
+		}
+		return a, o, nil
+	}
+	if n, ok := tok.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, &keyOrder{num: n}, nil
+	}
+	return tok, nil, nil
+}
+
+// addKeys adds the keys, in the order they were observed in an object, to
+// the object's key order.  A key that hasn't been seen before is placed
+// after the key that precedes it in keys.
+func (t *jsonType) addKeys(keys []string) {
+	for i, k := range keys {
+		if t.hasKey(k) {
+			continue
+		}
+		pos := 0
+		if i > 0 {
+			pos = t.keyIndex(keys[i-1]) + 1
+		}
+		t.order = append(t.order, "")
+		copy(t.order[pos+1:], t.order[pos:])
+		t.order[pos] = k
+	}
+}
+
+// hasKey returns whether the key is in the object's key order.
+func (t *jsonType) hasKey(key string) bool {
+	return t.keyIndex(key) >= 0
+}
+
+// keyIndex returns the index of the key within the object's key order, or
+// -1 if it isn't in it.
+func (t *jsonType) keyIndex(key string) int {
+	for i, k := range t.order {
+		if k == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// orderedFields returns the object's fields in the requested order.  If the
+// source order of the keys isn't known, source order is alphabetical.
+func (t *jsonType) orderedFields(order FieldOrder) []*field {
+	fields := t.sortedFields()
+	if order == Alphabetical || len(t.order) == 0 {
+		if order == RequiredFirst {
+			sort.Stable(requiredFirst{fields, t.objects})
+		}
+		return fields
+	}
+	// any fields whose position isn't known are after the ones that are,
+	// alphabetically
+	pos := make(map[string]int, len(t.order))
+	for i, k := range t.order {
+		pos[k] = i
+	}
+	sort.SliceStable(fields, func(i, j int) bool {
+		pi, iok := pos[fields[i].key]
+		pj, jok := pos[fields[j].key]
+		if iok != jok {
+			return iok
+		}
+		return pi < pj
+	})
+	if order == RequiredFirst {
+		sort.Stable(requiredFirst{fields, t.objects})
+	}
+	return fields
+}
+
+// requiredFirst sorts fields that were present in every object before the
+// ones that weren't.
+type requiredFirst struct {
+	fields  []*field
+	objects int
+}
+
+func (r requiredFirst) Len() int      { return len(r.fields) }
+func (r requiredFirst) Swap(i, j int) { r.fields[i], r.fields[j] = r.fields[j], r.fields[i] }
+func (r requiredFirst) Less(i, j int) bool {
+	return r.fields[i].count >= r.objects && r.fields[j].count < r.objects
+}
+
+// TypeOrder is the order of the type declarations in the output.  The first
+// type is always the one being generated; the order applies to the types
+// that were defined for its fields.  Each enum immediately follows the
+// struct that uses it.  Since types are named, and nested types are found,
+// in field order, the order of the declarations is stable for as long as
+// the field order is, e.g. alphabetical field order is not affected by the
+// order of the keys in the source.
+type TypeOrder int
+
+const (
+	// BreadthFirst declares the types for each level of nesting after the
+	// level before it.  This is the default.
+	BreadthFirst TypeOrder = iota
+	// DepthFirst declares the types for a struct's fields right after it,
+	// so that each type is next to its parent.
+	DepthFirst
+	// ByName declares the types sorted by name.
+	ByName
+)
+
+// ParseTypeOrder returns the TypeOrder for s, which is one of breadth,
+// depth, or alpha.
+func ParseTypeOrder(s string) (TypeOrder, error) {
+	switch s {
+	case "breadth", "bfs":
+		return BreadthFirst, nil
+	case "depth", "dfs":
+		return DepthFirst, nil
+	case "alpha", "alphabetical":
+		return ByName, nil
+	}
+	return BreadthFirst, fmt.Errorf("unknown type order %q: expected breadth, depth, or alpha", s)
+}
+
+// orderTypeDefs returns the type definitions, which are in breadth first
+// order, in the requested order.
+func orderTypeDefs(defs []TypeDef, order TypeOrder) []TypeDef {
+	if order == BreadthFirst || len(defs) == 0 {
+		return defs
+	}
+	isChild := map[string]bool{}
+	for _, def := range defs {
+		for _, c := range def.children {
+			isChild[c] = true
+		}
+	}
+	// group each struct, or map, with the definitions that follow it;
+	// an enum that other types refer to, e.g. a component, is a group of
+	// its own
+	var groups [][]TypeDef
+	for _, def := range defs {
+		if def.Kind == "enum" && !isChild[def.Name] && len(groups) > 0 {
+			groups[len(groups)-1] = append(groups[len(groups)-1], def)
+			continue
+		}
+		groups = append(groups, []TypeDef{def})
+	}
+	byName := make(map[string][]TypeDef, len(groups))
+	for _, g := range groups {
+		byName[g[0].Name] = g
+	}
+	// the types that aren't defined for a field, i.e. the one being
+	// generated, are first
+	ordered := make([]TypeDef, 0, len(defs))
+	var children [][]TypeDef
+	for _, g := range groups {
+		if !isChild[g[0].Name] {
+			ordered = append(ordered, g...)
+			continue
+		}
+		children = append(children, g)
+	}
+	switch order {
+	case DepthFirst:
+		// a type may be the child of more than one type, e.g. the
+		// Page that pages are aliases of, but it's only declared once
+		visited := map[string]bool{}
+		var visit func(name string)
+		visit = func(name string) {
+			if visited[name] {
+				return
+			}
+			visited[name] = true
+			// most enums are declared with the group they follow
+			g, ok := byName[name]
+			if !ok {
+				return
+			}
+			ordered = append(ordered, g...)
+			for _, c := range g[0].children {
+				visit(c)
+			}
+		}
+		roots := ordered
+		ordered = make([]TypeDef, 0, len(defs))
+		for _, def := range roots {
+			if def.Kind == "enum" && !isChild[def.Name] {
+				continue
+			}
+			visit(def.Name)
+		}
+		// types that are only reachable from each other, e.g.
+		// components that refer to one another, follow the rest
+		for _, g := range groups {
+			visit(g[0].Name)
+		}
+	case ByName:
+		sort.SliceStable(children, func(i, j int) bool { return children[i][0].Name < children[j][0].Name })
+		for _, g := range children {
+			ordered = append(ordered, g...)
+		}
+	}
+	return ordered
+}
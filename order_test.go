@@ -0,0 +1,152 @@
+package json2go
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDecodeOrdered(t *testing.T) {
+	tests := []struct {
+		json string
+		keys []string
+		err  bool
+	}{
+		{`{"b": 1, "a": {"z": 1, "y": 2}, "c": [1, 2]}`, []string{"b", "a", "c"}, false},
+		{`{"b": 1, "a": 2, "b": 3}`, []string{"b", "a"}, false},
+		{`[{"b": 1}, {"a": 2}]`, nil, false},
+		{`"str"`, nil, false},
+		{`{"a": 1}{"b": 2}`, nil, true},
+		{`{"a": 1`, nil, true},
+		{``, nil, true},
+	}
+	for i, test := range tests {
+		v, o, err := decodeOrdered([]byte(test.json))
+		if err != nil {
+			if !test.err {
+				t.Errorf("%d: unexpected error: %s", i, err)
+			}
+			continue
+		}
+		if test.err {
+			t.Errorf("%d: expected an error, got none", i)
+			continue
+		}
+		var want interface{}
+		err = json.Unmarshal([]byte(test.json), &want)
+		if err != nil {
+			t.Errorf("%d: unexpected unmarshal error: %s", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(v, want) {
+			t.Errorf("%d: got %#v want %#v", i, v, want)
+		}
+		var keys []string
+		if o != nil {
+			keys = o.keys
+		}
+		if !reflect.DeepEqual(keys, test.keys) {
+			t.Errorf("%d: got %q want %q", i, keys, test.keys)
+		}
+	}
+}
+
+func TestOrderedFields(t *testing.T) {
+	samples := []string{
+		`{"zeta": 1, "alpha": 2, "mid": 3}`,
+		`{"zeta": 1, "beta": 2, "alpha": 3}`,
+		`{"zeta": 1, "alpha": 2, "omega": 3}`,
+	}
+	var vals []interface{}
+	var orders []*keyOrder
+	for _, s := range samples {
+		v, o, err := decodeOrdered([]byte(s))
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		vals = append(vals, v)
+		orders = append(orders, o)
+	}
+	tests := []struct {
+		order    FieldOrder
+		expected []string
+	}{
+		{Alphabetical, []string{"alpha", "beta", "mid", "omega", "zeta"}},
+		{SourceOrder, []string{"zeta", "beta", "alpha", "omega", "mid"}},
+		{RequiredFirst, []string{"zeta", "alpha", "beta", "omega", "mid"}},
+	}
+	typ := inferType(vals, orders)
+	for _, test := range tests {
+		var keys []string
+		for _, f := range typ.orderedFields(test.order) {
+			keys = append(keys, f.key)
+		}
+		if !reflect.DeepEqual(keys, test.expected) {
+			t.Errorf("%d: got %q want %q", test.order, keys, test.expected)
+		}
+	}
+	// without the source order, required first falls back to alphabetical
+	typ = inferType(vals, nil)
+	var keys []string
+	for _, f := range typ.orderedFields(RequiredFirst) {
+		keys = append(keys, f.key)
+	}
+	expected := []string{"alpha", "zeta", "beta", "mid", "omega"}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("got %q want %q", keys, expected)
+	}
+}
+
+func TestParseFieldOrder(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected FieldOrder
+		err      bool
+	}{
+		{"alpha", Alphabetical, false},
+		{"source", SourceOrder, false},
+		{"required", RequiredFirst, false},
+		{"random", Alphabetical, true},
+	}
+	for _, test := range tests {
+		o, err := ParseFieldOrder(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if o != test.expected {
+			t.Errorf("%s: got %d want %d", test.s, o, test.expected)
+		}
+	}
+}
+
+func TestOrderTypeDefs(t *testing.T) {
+	// as generated: a map whose struct has an enum and two nested structs,
+	// one of which has a nested struct
+	defs := []TypeDef{
+		{Name: "Things", Kind: "map"},
+		{Name: "Thing", Kind: "struct", children: []string{"Zed", "Abc"}},
+		{Name: "Status", Kind: "enum"},
+		{Name: "Zed", Kind: "struct", children: []string{"Inner"}},
+		{Name: "Abc", Kind: "struct"},
+		{Name: "Inner", Kind: "struct"},
+		{Name: "Color", Kind: "enum"},
+	}
+	tests := []struct {
+		order    TypeOrder
+		expected []string
+	}{
+		{BreadthFirst, []string{"Things", "Thing", "Status", "Zed", "Abc", "Inner", "Color"}},
+		{DepthFirst, []string{"Things", "Thing", "Status", "Zed", "Inner", "Color", "Abc"}},
+		{ByName, []string{"Things", "Thing", "Status", "Abc", "Inner", "Color", "Zed"}},
+	}
+	for _, test := range tests {
+		var names []string
+		for _, def := range orderTypeDefs(defs, test.order) {
+			names = append(names, def.Name)
+		}
+		if !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("%d: got %q want %q", test.order, names, test.expected)
+		}
+	}
+}
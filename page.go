@@ -0,0 +1,251 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mohae/firkin/queue"
+)
+
+// pageRoot is a type that is generated from the input, which may be a page:
+// an envelope, e.g. {"data": [...], "next_cursor": "...", "total": 42},
+// around the items of a list.
+type pageRoot struct {
+	name string
+	typ  *jsonType
+	// path is the JSON path of the type.
+	path string
+	// item is what the type of a page's items is named from; if empty,
+	// it is the singular form of the key of the items.
+	item string
+}
+
+// SetPageKeys sets the shape of the pagination envelope: data is the key
+// whose value is the page's items and keys are the other keys the envelope
+// may have, e.g. SetPageKeys("data", "next_cursor", "total").  A type that
+// has data, as an array of objects, and no keys other than those is a page.
+// Setting the keys sets Pages.
+func (t *Transmogrifier) SetPageKeys(data string, keys ...string) {
+	t.Pages = true
+	t.pageKeys = append([]string{data}, keys...)
+}
+
+// enqueueRoots enqueues the struct definitions of the roots.  If Pages is
+// set, the roots that are pages are defined as aliases of the generic Page[T]
+// type, which is defined once, with T being the type of their items, e.g.
+// type ListUsersResponse = Page[User].  The aliases, and the definitions of
+// the roots that aren't structs, see enqueueRoot, are returned.
+func (t *Transmogrifier) enqueueRoots(q *queue.Queue, roots []pageRoot) []TypeDef {
+	var key string
+	var pages []int
+	if t.Pages && t.Format == GoSource {
+		key, pages = t.findPages(roots)
+	}
+	var defs []TypeDef
+	if len(pages) == 0 {
+		for _, r := range roots {
+			if def, ok := t.enqueueRoot(q, r); ok {
+				defs = append(defs, def)
+			}
+		}
+		return defs
+	}
+	env := pageEnvelope(roots, pages, key)
+	name := t.typeName("Page")
+	q.Enqueue(newGenericStructDef(name, "T any", env, "$"))
+	isPage := make(map[int]bool, len(pages))
+	for _, i := range pages {
+		isPage[i] = true
+	}
+	for i, r := range roots {
+		if !isPage[i] {
+			if def, ok := t.enqueueRoot(q, r); ok {
+				defs = append(defs, def)
+			}
+			continue
+		}
+		children := []string{name}
+		// the items of a page that was always empty are of an
+		// unknown type
+		item := "interface{}"
+		if items := r.typ.fields[key].typ; !isEmptyArray(items) {
+			item = r.item
+			if item == "" {
+				item = t.singularize(key)
+			}
+			item = t.typeName(t.getNamer().TypeName(declName(items.elem, item)))
+			q.Enqueue(newStructDef(item, items.elem, jsonPath(r.path, key)+"[*]"))
+			children = append(children, item)
+		}
+		typ := fmt.Sprintf("%s[%s]", name, item)
+		defs = append(defs, TypeDef{Name: r.name, Kind: "alias", Type: typ, Source: fmt.Sprintf("type %s = %s", r.name, typ), children: children})
+	}
+	return defs
+}
+
+// enqueueRoot enqueues the struct definition of the root if it is an object.
+// Otherwise, the definition of the root, a slice, e.g. type Users []User, or
+// a scalar, e.g. type Count int, is returned and the definition of the
+// struct of the objects it is composed of, if any, is enqueued.
+func (t *Transmogrifier) enqueueRoot(q *queue.Queue, r pageRoot) (TypeDef, bool) {
+	if r.typ.kind == objectKind && r.typ.mapOf == nil {
+		q.Enqueue(newStructDef(r.name, r.typ, r.path))
+		return TypeDef{}, false
+	}
+	def := TypeDef{Name: r.name, Kind: "scalar"}
+	if r.typ.kind == arrayKind {
+		def.Kind = "slice"
+	} else if r.typ.kind == objectKind {
+		def.Kind = "map"
+	}
+	var item string
+	if obj := r.typ.object(); obj != nil && obj.name != "" {
+		// the objects' struct has already been defined, e.g. a
+		// component
+		item = obj.name
+		def.children = []string{item}
+	} else if obj != nil {
+		item = t.typeName(t.getNamer().TypeName(declName(obj, r.item)))
+		path := r.path
+		for typ := r.typ; typ != obj; {
+			if typ.kind == arrayKind {
+				path, typ = path+"[*]", typ.elem
+				continue
+			}
+			path, typ = path+".*", typ.mapOf
+		}
+		q.Enqueue(newStructDef(item, obj, path))
+		def.children = []string{item}
+	}
+	def.Type = r.typ.goType(item)
+	if strings.Contains(def.Type, "time.Time") {
+		t.addImport("time")
+		def.imports = []string{"time"}
+	}
+	def.Source = fmt.Sprintf("type %s %s", r.name, def.Type)
+	return def, true
+}
+
+// findPages returns the key of the items and the indexes of the roots that
+// are pages.  If the shape of the envelope was set, every root with that
+// shape is a page.  Otherwise, the shape is learned: the roots that have the
+// same keys, one of which is an array of objects, or was always empty, are
+// pages if there are at least two of them.  If more than one shape is shared,
+// the most common one is used.
+func (t *Transmogrifier) findPages(roots []pageRoot) (string, []int) {
+	if len(t.pageKeys) > 0 {
+		key := t.pageKeys[0]
+		var pages []int
+		for i, r := range roots {
+			if k, ok := pageItems(r.typ); ok && k == key && keySubsetOf(r.typ, t.pageKeys) {
+				pages = append(pages, i)
+			}
+		}
+		return key, pages
+	}
+	shapes := map[string][]int{}
+	var order []string
+	for i, r := range roots {
+		key, ok := pageItems(r.typ)
+		if !ok || len(r.typ.fields) < 2 {
+			continue
+		}
+		keys := make([]string, 0, len(r.typ.fields))
+		for k := range r.typ.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		shape := key + ":" + strings.Join(keys, ",")
+		if shapes[shape] == nil {
+			order = append(order, shape)
+		}
+		shapes[shape] = append(shapes[shape], i)
+	}
+	var key string
+	var pages []int
+	for _, shape := range order {
+		if len(shapes[shape]) > len(pages) && len(shapes[shape]) > 1 {
+			key, pages = strings.SplitN(shape, ":", 2)[0], shapes[shape]
+		}
+	}
+	return key, pages
+}
+
+// pageItems returns the key of the field of the object that is the items if
+// it is a page: its only field that is an array of objects or, if it doesn't
+// have one, its only field that is an array that was always empty, e.g. the
+// data of {"data": [], "total": 0}.
+func pageItems(t *jsonType) (string, bool) {
+	if t.kind != objectKind || t.mapOf != nil {
+		return "", false
+	}
+	var key, empty string
+	var empties int
+	for k, f := range t.fields {
+		if isEmptyArray(f.typ) {
+			empty = k
+			empties++
+			continue
+		}
+		if f.typ.kind != arrayKind || f.typ.elem.kind != objectKind || f.typ.elem.mapOf != nil {
+			continue
+		}
+		if key != "" {
+			return "", false
+		}
+		key = k
+	}
+	if key == "" && empties == 1 {
+		key = empty
+	}
+	return key, key != ""
+}
+
+// isEmptyArray returns whether the type is an array that was always empty, so
+// the type of its elements is unknown.
+func isEmptyArray(t *jsonType) bool {
+	return t.kind == arrayKind && (t.elem == nil || t.elem.kind == nullKind)
+}
+
+// keySubsetOf returns whether every key of the object is one of keys.
+func keySubsetOf(t *jsonType, keys []string) bool {
+	for k := range t.fields {
+		found := false
+		for _, key := range keys {
+			if k == key {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// pageEnvelope returns the type of the envelope of the pages: the fields of
+// every page, other than the items, merged, with the items being a []T.
+func pageEnvelope(roots []pageRoot, pages []int, key string) *jsonType {
+	env := &jsonType{kind: objectKind, fields: map[string]*field{}}
+	for _, i := range pages {
+		typ := roots[i].typ
+		env.objects += typ.objects
+		env.addKeys(typ.order)
+		for k, f := range typ.fields {
+			ef, ok := env.fields[k]
+			if !ok {
+				ef = &field{key: k, typ: &jsonType{}}
+				env.fields[k] = ef
+			}
+			ef.count += f.count
+			if k != key {
+				ef.typ.merge(f.typ)
+			}
+		}
+	}
+	// T is a placeholder object that has already been named
+	env.fields[key].typ = &jsonType{kind: arrayKind, elem: &jsonType{kind: objectKind, name: "T"}}
+	return env
+}
@@ -0,0 +1,91 @@
+package json2go
+
+import (
+	"bytes"
+	"testing"
+)
+
+var pagesHAR = []byte(`{
+	"log": {
+		"entries": [
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/users"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"data\": [{\"id\": 1, \"name\": \"ann\"}], \"next_cursor\": \"abc\", \"total\": 2}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/teams/7/projects"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"data\": [{\"id\": 3, \"title\": \"x\"}], \"next_cursor\": \"def\", \"total\": 1}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/orgs"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"data\": [], \"next_cursor\": null, \"total\": 0}"}}
+			},
+			{
+				"request": {"method": "GET", "url": "https://api.example.com/me"},
+				"response": {"status": 200, "content": {"mimeType": "application/json", "text": "{\"id\": 1, \"name\": \"ann\"}"}}
+			}
+		]
+	}
+}`)
+
+func TestPages(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     Input
+		format   OutputFormat
+		keys     []string
+		input    string
+		expected string
+	}{
+		{"har", HAR, GoSource, nil, string(pagesHAR), "package main\n\ntype GetUsersResponse = Page[User]\n\ntype GetTeamsIDProjectsResponse = Page[Project]\n\ntype GetOrgsResponse = Page[interface{}]\n\ntype Page[T any] struct {\n\tData       []T    `json:\"data\"`\n\tNextCursor string `json:\"next_cursor\"`\n\tTotal      int    `json:\"total\"`\n}\n\ntype User struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n}\n\ntype Project struct {\n\tID    int    `json:\"id\"`\n\tTitle string `json:\"title\"`\n}\n\ntype GetMeResponse struct {\n\tID   int    `json:\"id\"`\n\tName string `json:\"name\"`\n}\n"},
+		{"keys", Samples, GoSource, []string{"items", "total", "next"}, `{"items": [{"id": 1}], "total": 1}`, "package main\n\ntype Users = Page[Item]\n\ntype Page[T any] struct {\n\tItems []T `json:\"items\"`\n\tTotal int `json:\"total\"`\n}\n\ntype Item struct {\n\tID int `json:\"id\"`\n}\n"},
+		{"single", Samples, GoSource, nil, `{"items": [{"id": 1}], "total": 1}`, "package main\n\ntype Users struct {\n\tItems []Item `json:\"items\"`\n\tTotal int    `json:\"total\"`\n}\n\ntype Item struct {\n\tID int `json:\"id\"`\n}\n"},
+		{"schema", Samples, JSONSchema, []string{"items", "total"}, `{"items": [{"id": 1}], "total": 1}`, "{\n\t\"$schema\": \"https://json-schema.org/draft/2020-12/schema\",\n\t\"title\": \"Users\",\n\t\"type\": \"object\",\n\t\"properties\": {\n\t\t\"items\": {\n\t\t\t\"type\": \"array\",\n\t\t\t\"items\": {\n\t\t\t\t\"type\": \"object\",\n\t\t\t\t\"properties\": {\n\t\t\t\t\t\"id\": {\n\t\t\t\t\t\t\"type\": \"integer\"\n\t\t\t\t\t}\n\t\t\t\t},\n\t\t\t\t\"required\": [\n\t\t\t\t\t\"id\"\n\t\t\t\t]\n\t\t\t}\n\t\t},\n\t\t\"total\": {\n\t\t\t\"type\": \"integer\"\n\t\t}\n\t},\n\t\"required\": [\n\t\t\"items\",\n\t\t\"total\"\n\t]\n}\n"},
+	}
+	for _, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("users", bytes.NewReader([]byte(test.input)), &buff)
+		calvin.From = test.from
+		calvin.Format = test.format
+		calvin.FieldOrder = SourceOrder
+		calvin.Pages = true
+		if test.keys != nil {
+			calvin.SetPageKeys(test.keys[0], test.keys[1:]...)
+		}
+		calvin.Verify = test.format == GoSource
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.name, err)
+			continue
+		}
+		if buff.String() != test.expected {
+			t.Errorf("%s: got %q want %q", test.name, buff.String(), test.expected)
+		}
+	}
+}
+
+func TestPageTemplate(t *testing.T) {
+	tmpl := `package main
+{{range .Types}}
+{{if eq .Kind "alias"}}type {{.Name}} = {{.Type}}{{else}}type {{.Name}}{{if .TypeParams}}[{{.TypeParams}}]{{end}} struct {
+{{range .Fields}}	{{.Name}} {{.GoType}} {{.Tag}}
+{{end}}}{{end}}
+{{end}}`
+	expected := "package main\n\ntype Users = Page[Item]\n\ntype Page[T any] struct {\n\tItems []T `json:\"items\"`\n\tTotal int `json:\"total\"`\n}\n\ntype Item struct {\n\tID int `json:\"id\"`\n}\n"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("users", bytes.NewReader([]byte(`{"items": [{"id": 1}], "total": 1}`)), &buff)
+	calvin.FieldOrder = SourceOrder
+	calvin.SetPageKeys("items", "total")
+	calvin.Verify = true
+	err := calvin.SetTemplate(tmpl)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
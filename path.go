@@ -0,0 +1,186 @@
+package json2go
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SetPath sets the JSON Pointer, RFC 6901, of the node within the input that
+// the types are generated from, e.g. /data/items.  A * token selects every
+// element of an array, or every value of an object; the nodes that are
+// selected are samples of the type, with the elements of any that are arrays
+// being samples.  An empty path selects the whole input.  The input must be
+// samples.  WriteJSON still writes the whole input.
+func (t *Transmogrifier) SetPath(p string) error {
+	tokens, err := parsePointer(p)
+	if err != nil {
+		return err
+	}
+	t.path = tokens
+	return nil
+}
+
+// parsePointer returns the unescaped reference tokens of the JSON Pointer.
+func parsePointer(p string) ([]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	if p[0] != '/' {
+		return nil, fmt.Errorf("invalid path %q: a JSON Pointer must start with /", p)
+	}
+	tokens := strings.Split(p[1:], "/")
+	for i, tok := range tokens {
+		for j := 0; j < len(tok); j++ {
+			if tok[j] == '~' && (j+1 == len(tok) || tok[j+1] != '0' && tok[j+1] != '1') {
+				return nil, fmt.Errorf("invalid path %q: ~ must be followed by 0 or 1", p)
+			}
+		}
+		tokens[i] = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+	}
+	return tokens, nil
+}
+
+// pathNode is a node that a path selected and its key order.
+type pathNode struct {
+	v interface{}
+	o *keyOrder
+}
+
+// selectPath returns the node within the decoded JSON, v, that the tokens
+// select, its key order, and its JSON path, which is the root path of the
+// generated types.  If a token is *, every node that is selected is a sample:
+// the result is an array of them, with nodes that are arrays replaced by
+// their elements.
+func selectPath(v interface{}, o *keyOrder, tokens []string) (interface{}, *keyOrder, string, error) {
+	nodes := []pathNode{{v, o}}
+	path := "$"
+	wild := false
+	for i, tok := range tokens {
+		ptr := pointerString(tokens[:i+1])
+		var next []pathNode
+		for _, n := range nodes {
+			switch v := n.v.(type) {
+			case map[string]interface{}:
+				if tok == "*" {
+					keys := sortedKeys(v)
+					if n.o != nil && len(n.o.keys) == len(v) {
+						keys = n.o.keys
+					}
+					for _, k := range keys {
+						next = append(next, pathNode{v[k], n.o.child(k)})
+					}
+					continue
+				}
+				c, ok := v[tok]
+				if !ok {
+					if wild {
+						continue
+					}
+					return nil, nil, "", fmt.Errorf("path %s: key %q not found", ptr, tok)
+				}
+				next = append(next, pathNode{c, n.o.child(tok)})
+			case []interface{}:
+				if tok == "*" {
+					for j, e := range v {
+						next = append(next, pathNode{e, n.o.elem(j)})
+					}
+					continue
+				}
+				j, err := strconv.Atoi(tok)
+				if err != nil || j < 0 || (len(tok) > 1 && tok[0] == '0') {
+					return nil, nil, "", fmt.Errorf("path %s: %q is not an array index", ptr, tok)
+				}
+				if j >= len(v) {
+					if wild {
+						continue
+					}
+					return nil, nil, "", fmt.Errorf("path %s: index %d is out of range", ptr, j)
+				}
+				next = append(next, pathNode{v[j], n.o.elem(j)})
+			default:
+				if wild {
+					continue
+				}
+				return nil, nil, "", fmt.Errorf("path %s: %s is not an object or an array", ptr, pointerString(tokens[:i]))
+			}
+		}
+		switch _, err := strconv.Atoi(tok); {
+		case tok == "*":
+			wild = true
+			path += "[*]"
+		case err == nil:
+			path += "[" + tok + "]"
+		default:
+			path = jsonPath(path, tok)
+		}
+		nodes = next
+	}
+	if !wild {
+		return nodes[0].v, nodes[0].o, path + rootPath(nodes[0].v)[1:], nil
+	}
+	if len(nodes) == 0 {
+		return nil, nil, "", fmt.Errorf("path %s: no nodes were selected", pointerString(tokens))
+	}
+	var samples []interface{}
+	orders := &keyOrder{}
+	spread := false
+	for _, n := range nodes {
+		a, ok := n.v.([]interface{})
+		if !ok {
+			samples = append(samples, n.v)
+			orders.elems = append(orders.elems, n.o)
+			continue
+		}
+		spread = true
+		for j, e := range a {
+			samples = append(samples, e)
+			orders.elems = append(orders.elems, n.o.elem(j))
+		}
+	}
+	if spread {
+		path += "[*]"
+	}
+	return samples, orders, path, nil
+}
+
+// pointerString returns the JSON Pointer of the tokens.
+func pointerString(tokens []string) string {
+	if len(tokens) == 0 {
+		return `""`
+	}
+	var b strings.Builder
+	for _, tok := range tokens {
+		b.WriteString("/" + escapePointer(tok))
+	}
+	return b.String()
+}
+
+// orderedValue returns the decoded JSON, v, with its objects as
+// *orderedObjects, so that it is encoded with its keys in their original
+// order, and its numbers as they were written.
+func orderedValue(v interface{}, o *keyOrder) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := sortedKeys(v)
+		if o != nil && len(o.keys) == len(v) {
+			keys = o.keys
+		}
+		obj := &orderedObject{}
+		for _, k := range keys {
+			obj.set(k, orderedValue(v[k], o.child(k)))
+		}
+		return obj
+	case []interface{}:
+		a := make([]interface{}, len(v))
+		for i, e := range v {
+			a[i] = orderedValue(e, o.elem(i))
+		}
+		return a
+	case float64:
+		if o != nil && o.num != "" {
+			return o.num
+		}
+	}
+	return v
+}
@@ -0,0 +1,168 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var envelope = []byte(`{
+	"meta": {"page": 1},
+	"data": {
+		"items": [
+			{"id": 1, "name": "a"},
+			{"id": 2, "name": "b", "tags": ["x"]}
+		]
+	}
+}`)
+
+func TestPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		json     string
+	}{
+		{"/data/items", "package main\n\ntype Item struct {\n\tID   int      `json:\"id\"`\n\tName string   `json:\"name\"`\n\tTags []string `json:\"tags\"`\n}\n", ""},
+		{"/data/items/1", "package main\n\ntype Item struct {\n\tID   int      `json:\"id\"`\n\tName string   `json:\"name\"`\n\tTags []string `json:\"tags\"`\n}\n", ""},
+		{"/data/items/*", "package main\n\ntype Item struct {\n\tID   int      `json:\"id\"`\n\tName string   `json:\"name\"`\n\tTags []string `json:\"tags\"`\n}\n", ""},
+		{"/meta", "package main\n\ntype Item struct {\n\tPage int `json:\"page\"`\n}\n", ""},
+		{"/*", "package main\n\ntype Item struct {\n\tItems []Item2 `json:\"items\"`\n\tPage  int     `json:\"page\"`\n}\n\ntype Item2 struct {\n\tID   int      `json:\"id\"`\n\tName string   `json:\"name\"`\n\tTags []string `json:\"tags\"`\n}\n", ""},
+	}
+	for _, test := range tests {
+		var buff, jsn bytes.Buffer
+		calvin := NewTransmogrifier("item", bytes.NewReader(envelope), &buff)
+		calvin.WriteJSON = true
+		calvin.SetJSONWriter(&jsn)
+		err := calvin.SetPath(test.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		err = calvin.Gen()
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		if buff.String() != test.expected {
+			t.Errorf("%s: got %q want %q", test.path, buff.String(), test.expected)
+		}
+		// the whole input is written
+		if jsn.String() != string(envelope) {
+			t.Errorf("%s: got JSON %q want %q", test.path, jsn.String(), envelope)
+		}
+	}
+}
+
+func TestPathErrors(t *testing.T) {
+	tests := []struct {
+		path string
+		err  string
+	}{
+		{"data", "must start with /"},
+		{"/a~2", "~ must be followed by 0 or 1"},
+		{"/data/missing", `path /data/missing: key "missing" not found`},
+		{"/data/items/5", "index 5 is out of range"},
+		{"/data/items/01", `"01" is not an array index`},
+		{"/meta/page/x", "/meta/page is not an object or an array"},
+		{"/meta/*/x", "no nodes were selected"},
+	}
+	for _, test := range tests {
+		var buff bytes.Buffer
+		calvin := NewTransmogrifier("item", bytes.NewReader(envelope), &buff)
+		err := calvin.SetPath(test.path)
+		if err == nil {
+			err = calvin.Gen()
+		}
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.path, err, test.err)
+		}
+	}
+}
+
+func TestSelectPath(t *testing.T) {
+	tests := []struct {
+		json     string
+		path     string
+		expected string
+		jsonPath string
+	}{
+		{`{"a": {"b c": [1, 2]}}`, "/a/b c", `[1,2]`, `$.a["b c"][*]`},
+		{`{"a/b": {"~": true}}`, "/a~1b/~0", `true`, `$["a/b"]["~"]`},
+		{`{"x": [{"v": [1]}, {"v": [2, 3]}, {}]}`, "/x/*/v", `[1,2,3]`, `$.x[*].v[*]`},
+		{`{"b": {"id": 1}, "a": {"id": 2}}`, "/*", `[{"id":1},{"id":2}]`, `$[*]`},
+		{`[{"a": 1}]`, "", `[{"a":1}]`, `$[*]`},
+	}
+	for _, test := range tests {
+		v, o, err := decodeOrdered([]byte(test.json))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		tokens, err := parsePointer(test.path)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		v, o, p, err := selectPath(v, o, tokens)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		b, err := json.Marshal(orderedValue(v, o))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %s", test.path, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%s: got %s want %s", test.path, b, test.expected)
+		}
+		if p != test.jsonPath {
+			t.Errorf("%s: got path %s want %s", test.path, p, test.jsonPath)
+		}
+	}
+}
+
+// TestPathNumbers tests that the selected node is verified, and tested, with
+// its numbers as they were written.
+func TestPathNumbers(t *testing.T) {
+	input := `{"data": {"x": 72.0, "n": 9007199254740993}}`
+	for _, path := range []string{"", "/data"} {
+		var buff bytes.Buffer
+		doc := input
+		if path == "" {
+			doc = `{"x": 72.0, "n": 9007199254740993}`
+		}
+		calvin := NewTransmogrifier("item", strings.NewReader(doc), &buff)
+		calvin.Verify = true
+		if path != "" {
+			err := calvin.SetPath(path)
+			if err != nil {
+				t.Fatalf("%q: unexpected error: %s", path, err)
+			}
+		}
+		err := calvin.Gen()
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", path, err)
+			continue
+		}
+		if !strings.Contains(buff.String(), "X float64") {
+			t.Errorf("%q: expected x, 72.0, to be a float64, got %q", path, buff.String())
+		}
+	}
+	var buff, tbuff bytes.Buffer
+	calvin := NewTransmogrifier("item", strings.NewReader(input), &buff)
+	calvin.GenTest = true
+	calvin.SetTestWriter(&tbuff)
+	err := calvin.SetPath("/data")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	err = calvin.Gen()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if !strings.Contains(tbuff.String(), "9007199254740993") {
+		t.Errorf("expected the test's JSON to have 9007199254740993, got %q", tbuff.String())
+	}
+}
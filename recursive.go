@@ -0,0 +1,150 @@
+package json2go
+
+// foldRecursive finds nested objects that have the same shape as one of
+// their enclosing objects, e.g. the children of a node in a tree, and
+// replaces them with a reference to the enclosing object.  The nested
+// object is merged into the enclosing one so that nothing observed is lost.
+// Without this, every level of a recursive structure would result in its
+// own struct definition, all with the same name.
+func (t *jsonType) foldRecursive(ancestors []*jsonType) {
+	ancestors = append(ancestors, t)
+	done := make(map[string]struct{}, len(t.fields))
+	for {
+		// merging may add fields to t, so get the fields each time
+		var f *field
+		for _, v := range t.sortedFields() {
+			if _, ok := done[v.key]; !ok {
+				f = v
+				break
+			}
+		}
+		if f == nil {
+			return
+		}
+		done[f.key] = struct{}{}
+		obj := f.typ.object()
+		if obj == nil || isAncestor(ancestors, obj) {
+			continue
+		}
+		if a := recursiveMatch(ancestors, f.key, obj); a != nil {
+			f.setObject(a)
+			a.merge(obj)
+			continue
+		}
+		obj.foldRecursive(ancestors)
+	}
+}
+
+// isAncestor returns whether t is one of the ancestors.
+func isAncestor(ancestors []*jsonType, t *jsonType) bool {
+	for _, a := range ancestors {
+		if a == t {
+			return true
+		}
+	}
+	return false
+}
+
+// recursiveMatch returns the closest ancestor that obj, which was reached
+// through key, is a recursion of; nil is returned if there isn't one.  For
+// obj to be a recursion of an ancestor, both must have key, the keys of one
+// must be a subset of the other's, and the values of their common keys
+// must be compatible.
+func recursiveMatch(ancestors []*jsonType, key string, obj *jsonType) *jsonType {
+	if _, ok := obj.fields[key]; !ok {
+		return nil
+	}
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		a := ancestors[i]
+		if _, ok := a.fields[key]; !ok {
+			continue
+		}
+		if !keySubset(a, obj) && !keySubset(obj, a) {
+			continue
+		}
+		if compatible(a, obj, map[[2]*jsonType]struct{}{}) {
+			return a
+		}
+	}
+	return nil
+}
+
+// keySubset returns whether the keys of a are a subset of the keys of b.
+func keySubset(a, b *jsonType) bool {
+	for k := range a.fields {
+		if _, ok := b.fields[k]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// compatible returns whether values of type a and b could be of the same
+// type.  Unknown types, i.e. nulls and empty arrays, are compatible with
+// everything.  For objects, only the common keys are compared.  The seen
+// pairs are used to stop on already folded, cyclic, types.
+func compatible(a, b *jsonType, seen map[[2]*jsonType]struct{}) bool {
+	if a == nil || b == nil || a == b || a.kind == nullKind || b.kind == nullKind {
+		return true
+	}
+	if _, ok := seen[[2]*jsonType{a, b}]; ok {
+		return true
+	}
+	seen[[2]*jsonType{a, b}] = struct{}{}
+	switch {
+	case a.kind == intKind && b.kind == floatKind, a.kind == floatKind && b.kind == intKind:
+		return true
+	case a.kind != b.kind:
+		return false
+	case a.kind == arrayKind:
+		return compatible(a.elem, b.elem, seen)
+	case a.kind == objectKind:
+		for k, af := range a.fields {
+			bf, ok := b.fields[k]
+			if !ok {
+				continue
+			}
+			if !compatible(af.typ, bf.typ, seen) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// setObject replaces the object that the field's type is composed of, see
+// object, with obj.
+func (f *field) setObject(obj *jsonType) {
+	if f.typ.kind == objectKind {
+		f.typ = obj
+		return
+	}
+	for t := f.typ; t.elem != nil; t = t.elem {
+		if t.elem.kind == objectKind {
+			t.elem = obj
+			return
+		}
+	}
+}
+
+// reaches returns whether the object t contains the object o, either because
+// they are the same or because one of t's fields, or their fields, is o.
+// Arrays and maps aren't followed; they don't make a struct contain itself.
+func (t *jsonType) reaches(o *jsonType, seen map[*jsonType]struct{}) bool {
+	if t == o {
+		return true
+	}
+	if seen == nil {
+		seen = map[*jsonType]struct{}{}
+	}
+	if _, ok := seen[t]; ok {
+		return false
+	}
+	seen[t] = struct{}{}
+	for _, f := range t.fields {
+		if f.typ.kind == objectKind && f.typ.mapOf == nil && f.typ.reaches(o, seen) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,507 @@
+package json2go
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Input is what the input JSON is.
+type Input int
+
+const (
+	// Samples is JSON that is one, or, if it is an array, more samples of
+	// the type.  This is the default.
+	Samples Input = iota
+	// Schema is a JSON Schema document, draft-07 or 2020-12, that
+	// describes the type.
+	Schema
+	// OpenAPI is an OpenAPI 3 document; a type is generated for each of
+	// its component schemas.
+	OpenAPI
+	// HAR is an HTTP Archive, e.g. traffic recorded by a browser or a
+	// proxy; a type is generated for the JSON responses of each endpoint.
+	HAR
+)
+
+// ParseInput returns the Input for s, which is one of samples, schema,
+// openapi, or har.
+func ParseInput(s string) (Input, error) {
+	switch s {
+	case "samples", "json":
+		return Samples, nil
+	case "schema":
+		return Schema, nil
+	case "openapi":
+		return OpenAPI, nil
+	case "har":
+		return HAR, nil
+	}
+	return Samples, fmt.Errorf("unknown input %q: expected samples, schema, openapi, or har", s)
+}
+
+// declName returns the name a type should be named from: the name of the
+// definition that declared it, if any, otherwise name.
+func declName(t *jsonType, name string) string {
+	if t.defName != "" {
+		return t.defName
+	}
+	return name
+}
+
+// schemaConv converts a JSON Schema to the types it declares.
+type schemaConv struct {
+	// root is the root schema, which $refs are resolved against.
+	root  interface{}
+	order *keyOrder
+	// refs are the types of the resolved $refs; a $ref is only converted
+	// once, so references to it, including recursive ones, share its type.
+	refs map[string]*jsonType
+}
+
+// schemaType returns the type that the decoded JSON Schema, with the key
+// order, declares.
+func schemaType(schema interface{}, order *keyOrder) (*jsonType, error) {
+	c := schemaConv{root: schema, order: order, refs: map[string]*jsonType{}}
+	return c.resolve("#")
+}
+
+// typeOf returns the type the schema, s, declares.
+func (c *schemaConv) typeOf(s interface{}, o *keyOrder) (*jsonType, error) {
+	switch s := s.(type) {
+	case bool:
+		// true allows anything; false allows nothing, which can't be
+		// represented, so it is treated the same
+		return &jsonType{declared: true}, nil
+	case map[string]interface{}:
+		if ref, ok := s["$ref"].(string); ok {
+			return c.resolve(ref)
+		}
+		if kw, i := soleAlternative(s); kw != "" {
+			return c.typeOf(s[kw].([]interface{})[i], o.child(kw).elem(i))
+		}
+		t := &jsonType{declared: true}
+		return t, c.declare(t, s, o)
+	}
+	return nil, fmt.Errorf("invalid schema: expected an object or a bool, got %s", inferType([]interface{}{s}, nil).kind)
+}
+
+// soleAlternative returns the keyword and index of the only alternative, other
+// than null, of a schema that is just a oneOf or anyOf, e.g. a nullable
+// $ref.  The alternative's type is used as is, so that a referenced
+// definition isn't copied.  If there isn't one, an empty keyword is
+// returned.
+func soleAlternative(s map[string]interface{}) (string, int) {
+	var kw string
+	for k := range s {
+		switch k {
+		case "oneOf", "anyOf":
+			if kw != "" {
+				return "", 0
+			}
+			kw = k
+		case "description", "title", "$comment":
+		default:
+			return "", 0
+		}
+	}
+	if kw == "" {
+		return "", 0
+	}
+	alts, _ := s[kw].([]interface{})
+	idx := -1
+	for i, alt := range alts {
+		if m, ok := alt.(map[string]interface{}); ok && m["type"] == "null" {
+			continue
+		}
+		if idx >= 0 {
+			return "", 0
+		}
+		idx = i
+	}
+	if idx < 0 {
+		return "", 0
+	}
+	return kw, idx
+}
+
+// resolve returns the type the $ref, a JSON Pointer within the schema,
+// refers to.
+func (c *schemaConv) resolve(ref string) (*jsonType, error) {
+	if t, ok := c.refs[ref]; ok {
+		return t, nil
+	}
+	s, o, name, err := c.lookup(ref)
+	if err != nil {
+		return nil, err
+	}
+	// the type is cached before it is declared so that recursive
+	// references resolve to it
+	t := &jsonType{declared: true, defName: name}
+	if ref == "#" {
+		// the root is named by the caller
+		t.defName = ""
+	}
+	c.refs[ref] = t
+	switch s := s.(type) {
+	case bool:
+	case map[string]interface{}:
+		if r, ok := s["$ref"].(string); ok {
+			rt, err := c.resolve(r)
+			if err != nil {
+				return nil, err
+			}
+			c.refs[ref] = rt
+			return rt, nil
+		}
+		err := c.declare(t, s, o)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid $ref %q: expected a schema", ref)
+	}
+	return t, nil
+}
+
+// lookup returns the value the $ref, a JSON Pointer within the document,
+// refers to, along with its key order and the last token of the pointer.
+func (c *schemaConv) lookup(ref string) (interface{}, *keyOrder, string, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, nil, "", fmt.Errorf("unsupported $ref %q: only references within the schema are supported", ref)
+	}
+	s, o := c.root, c.order
+	var name string
+	for _, tok := range strings.Split(strings.TrimPrefix(ref, "#"), "/")[1:] {
+		tok = strings.Replace(strings.Replace(tok, "~1", "/", -1), "~0", "~", -1)
+		switch v := s.(type) {
+		case map[string]interface{}:
+			s, o = v[tok], o.child(tok)
+		case []interface{}:
+			i, err := strconv.Atoi(tok)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil, nil, "", fmt.Errorf("invalid $ref %q: %q is not an index", ref, tok)
+			}
+			s, o = v[i], o.elem(i)
+		default:
+			s = nil
+		}
+		if s == nil {
+			return nil, nil, "", fmt.Errorf("invalid $ref %q: not found", ref)
+		}
+		name = tok
+	}
+	return s, o, name, nil
+}
+
+// declare sets t to what the schema, s, declares.
+func (c *schemaConv) declare(t *jsonType, s map[string]interface{}, o *keyOrder) error {
+	t.desc, _ = s["description"].(string)
+	t.format, _ = s["format"].(string)
+	// the type keyword is either a type or a list of them
+	var types []string
+	switch v := s["type"].(type) {
+	case string:
+		types = []string{v}
+	case []interface{}:
+		for _, e := range v {
+			if ts, ok := e.(string); ok {
+				types = append(types, ts)
+			}
+		}
+	}
+	if len(types) == 0 {
+		// without a type, it is implied by the keywords, if any
+		switch {
+		case s["properties"] != nil || s["additionalProperties"] != nil:
+			types = []string{"object"}
+		case s["items"] != nil || s["prefixItems"] != nil:
+			types = []string{"array"}
+		}
+	}
+	for _, typ := range types {
+		switch typ {
+		case "null":
+			t.nulls++
+		case "boolean":
+			t.setKind(boolKind)
+		case "integer":
+			t.setKind(intKind)
+		case "number":
+			t.setKind(floatKind)
+		case "string":
+			t.setKind(stringKind)
+		case "object":
+			t.setKind(objectKind)
+		case "array":
+			t.setKind(arrayKind)
+		default:
+			return fmt.Errorf("invalid schema: unknown type %q", typ)
+		}
+	}
+	if s["nullable"] == true {
+		// OpenAPI 3.0's nullable
+		t.nulls++
+	}
+	if vals, ok := s["enum"].([]interface{}); ok {
+		c.declareEnum(t, vals)
+	}
+	c.declareRanges(t, s)
+	if ex, ok := s["examples"].([]interface{}); ok && len(ex) > 0 {
+		c.declareExample(t, ex[0])
+	} else if ex, ok := s["example"]; ok {
+		c.declareExample(t, ex)
+	}
+	switch t.kind {
+	case objectKind:
+		err := c.declareObject(t, s, o)
+		if err != nil {
+			return err
+		}
+	case arrayKind:
+		err := c.declareArray(t, s, o)
+		if err != nil {
+			return err
+		}
+	}
+	// the alternatives are merged, like samples are; a null alternative
+	// makes the type nullable
+	for _, kw := range []string{"oneOf", "anyOf", "allOf"} {
+		alts, ok := s[kw].([]interface{})
+		if !ok {
+			continue
+		}
+		for i, alt := range alts {
+			if m, ok := alt.(map[string]interface{}); ok && m["type"] == "null" {
+				t.nulls++
+				continue
+			}
+			at, err := c.typeOf(alt, o.child(kw).elem(i))
+			if err != nil {
+				return err
+			}
+			t.mergeDeclared(at, kw == "allOf")
+		}
+	}
+	return nil
+}
+
+// declareEnum adds the enum's values to t.  If every value is a string, t is
+// an enum; otherwise the values only determine the kind.
+func (c *schemaConv) declareEnum(t *jsonType, vals []interface{}) {
+	strs := true
+	for _, v := range vals {
+		switch v.(type) {
+		case nil:
+			t.nulls++
+		case string:
+		default:
+			strs = false
+		}
+	}
+	for _, v := range vals {
+		if v == nil {
+			continue
+		}
+		if !strs {
+			k := inferType([]interface{}{v}, nil)
+			t.setKind(k.kind)
+			continue
+		}
+		t.setKind(stringKind)
+		if t.strVals == nil {
+			t.strVals = map[string]struct{}{}
+		}
+		t.strVals[v.(string)] = struct{}{}
+	}
+}
+
+// declareRanges sets t's range and lengths if both their minimum and maximum
+// are declared.
+func (c *schemaConv) declareRanges(t *jsonType, s map[string]interface{}) {
+	min, minOK := s["minimum"].(float64)
+	max, maxOK := s["maximum"].(float64)
+	if minOK && maxOK {
+		t.addNum(min)
+		t.addNum(max)
+	}
+	for _, kw := range [][2]string{{"minLength", "maxLength"}, {"minItems", "maxItems"}} {
+		min, minOK := s[kw[0]].(float64)
+		max, maxOK := s[kw[1]].(float64)
+		if minOK && maxOK {
+			t.addLen(int(min))
+			t.addLen(int(max))
+		}
+	}
+}
+
+// declareExample sets t's example, if it is a bool, number, or string.
+func (c *schemaConv) declareExample(t *jsonType, v interface{}) {
+	switch v.(type) {
+	case bool, float64, string:
+		t.addExample(v)
+	}
+}
+
+// declareObject sets t's fields, in the order of the properties, or, if
+// there are no properties, t is a map of the additionalProperties.
+func (c *schemaConv) declareObject(t *jsonType, s map[string]interface{}, o *keyOrder) error {
+	t.objects = 1
+	props, _ := s["properties"].(map[string]interface{})
+	if len(props) == 0 {
+		switch ap := s["additionalProperties"].(type) {
+		case bool:
+			if !ap {
+				// nothing is allowed, so it is an empty struct
+				t.fields = map[string]*field{}
+				return nil
+			}
+			t.mapOf = &jsonType{declared: true}
+		case nil:
+			t.mapOf = &jsonType{declared: true}
+		default:
+			mt, err := c.typeOf(ap, o.child("additionalProperties"))
+			if err != nil {
+				return err
+			}
+			t.mapOf = mt
+		}
+		return nil
+	}
+	required := map[string]bool{}
+	if req, ok := s["required"].([]interface{}); ok {
+		for _, r := range req {
+			if k, ok := r.(string); ok {
+				required[k] = true
+			}
+		}
+	}
+	po := o.child("properties")
+	t.fields = make(map[string]*field, len(props))
+	for k, p := range props {
+		pt, err := c.typeOf(p, po.child(k))
+		if err != nil {
+			return fmt.Errorf("property %q: %s", k, err)
+		}
+		f := &field{key: k, typ: pt}
+		if required[k] {
+			f.count = 1
+		}
+		t.fields[k] = f
+	}
+	if po != nil {
+		t.order = append(t.order, po.keys...)
+	} else {
+		t.order = append(t.order, sortedKeys(props)...)
+	}
+	return nil
+}
+
+// declareArray sets the type of t's elements: the items or, for tuples, the
+// merged prefixItems, or items array.
+func (c *schemaConv) declareArray(t *jsonType, s map[string]interface{}, o *keyOrder) error {
+	var items []interface{}
+	var io []*keyOrder
+	switch v := s["items"].(type) {
+	case nil:
+	case []interface{}:
+		for i, e := range v {
+			items = append(items, e)
+			io = append(io, o.child("items").elem(i))
+		}
+	default:
+		items = append(items, v)
+		io = append(io, o.child("items"))
+	}
+	if v, ok := s["prefixItems"].([]interface{}); ok {
+		for i, e := range v {
+			items = append(items, e)
+			io = append(io, o.child("prefixItems").elem(i))
+		}
+	}
+	if len(items) == 1 {
+		et, err := c.typeOf(items[0], io[0])
+		if err != nil {
+			return err
+		}
+		t.elem = et
+		return nil
+	}
+	for i, item := range items {
+		et, err := c.typeOf(item, io[i])
+		if err != nil {
+			return err
+		}
+		if t.elem == nil {
+			t.elem = &jsonType{declared: true}
+		}
+		t.elem.mergeDeclared(et, false)
+	}
+	return nil
+}
+
+// mergeDeclared merges a copy of the declared type o into t, so that o, which
+// may be shared by references to it, isn't changed.  If all is true, o is
+// one of the schemas that must all be satisfied, so the fields that o
+// requires are required; otherwise, only fields required by every merged
+// type are.
+func (t *jsonType) mergeDeclared(o *jsonType, all bool) {
+	t.merge(o.clone(map[*jsonType]*jsonType{}))
+	t.declared = true
+	if t.desc == "" {
+		t.desc = o.desc
+	}
+	if t.format == "" {
+		t.format = o.format
+	}
+	if t.kind != objectKind || !all {
+		return
+	}
+	t.objects = 1
+	for _, f := range t.fields {
+		if f.count > 1 {
+			f.count = 1
+		}
+	}
+}
+
+// clone returns a copy of t, whose fields, elements, and values are copies.
+// The copies are in seen so that recursive types are only copied once.
+func (t *jsonType) clone(seen map[*jsonType]*jsonType) *jsonType {
+	if t == nil {
+		return nil
+	}
+	if c, ok := seen[t]; ok {
+		return c
+	}
+	c := *t
+	seen[t] = &c
+	c.name = ""
+	c.order = append([]string(nil), t.order...)
+	if t.fields != nil {
+		c.fields = make(map[string]*field, len(t.fields))
+		for k, f := range t.fields {
+			c.fields[k] = &field{key: f.key, typ: f.typ.clone(seen), count: f.count}
+		}
+	}
+	if t.strVals != nil {
+		c.strVals = make(map[string]struct{}, len(t.strVals))
+		for k := range t.strVals {
+			c.strVals[k] = struct{}{}
+		}
+	}
+	c.elem = t.elem.clone(seen)
+	c.mapOf = t.mapOf.clone(seen)
+	return &c
+}
+
+// sortedKeys returns the keys of the map, sorted.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
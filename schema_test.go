@@ -0,0 +1,111 @@
+package json2go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var schema = []byte(`{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id"],
+	"properties": {
+		"id": {"type": "integer"},
+		"status": {"enum": ["active", "inactive"]},
+		"created": {"type": "string", "format": "date-time"},
+		"billing": {"$ref": "#/$defs/address"},
+		"shipping": {"oneOf": [{"$ref": "#/$defs/address"}, {"type": "null"}]},
+		"labels": {"type": "object", "additionalProperties": {"type": "string"}},
+		"lines": {"type": "array", "items": {"$ref": "#/definitions/line"}},
+		"parent": {"$ref": "#"},
+		"extra": {"anyOf": [{"type": "string"}, {"type": "integer"}]},
+		"size": {"oneOf": [{"type": "integer"}, {"type": "number"}]}
+	},
+	"$defs": {
+		"address": {"type": "object", "properties": {"street": {"type": "string"}}}
+	},
+	"definitions": {
+		"line": {"properties": {"sku": {"type": "string"}, "lines": {"type": "array", "items": {"$ref": "#/definitions/line"}}}}
+	}
+}`)
+
+func TestSchema(t *testing.T) {
+	expected := "package main\n\nimport (\n\t\"time\"\n)\n\ntype Order struct {\n\tID       int               `json:\"id\"`\n\tStatus   Status            `json:\"status\"`\n\tCreated  time.Time         `json:\"created\"`\n\tBilling  Address           `json:\"billing\"`\n\tShipping Address           `json:\"shipping\"`\n\tLabels   map[string]string `json:\"labels\"`\n\tLines    []Line            `json:\"lines\"`\n\tParent   *Order            `json:\"parent\"`\n\tExtra    interface{}       `json:\"extra\"`\n\tSize     float64           `json:\"size\"`\n}\n\ntype Status string\n\nconst (\n\tStatusActive   Status = \"active\"\n\tStatusInactive Status = \"inactive\"\n)\n\n// IsValid reports whether v is a known Status value.\nfunc (v Status) IsValid() bool {\n\tswitch v {\n\tcase StatusActive, StatusInactive:\n\t\treturn true\n\t}\n\treturn false\n}\n\ntype Address struct {\n\tStreet string `json:\"street\"`\n}\n\ntype Line struct {\n\tSku   string `json:\"sku\"`\n\tLines []Line `json:\"lines\"`\n}\n"
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("order", bytes.NewReader(schema), &buff)
+	calvin.From = Schema
+	calvin.FieldOrder = SourceOrder
+	calvin.Verify = true
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+func TestSchemaType(t *testing.T) {
+	tests := []struct {
+		schema   string
+		required []string
+		err      string
+	}{
+		{`{"properties": {"a": {}, "b": {}}, "required": ["a"]}`, []string{"a"}, ""},
+		{`{"allOf": [{"properties": {"a": {}}, "required": ["a"]}, {"properties": {"b": {}}, "required": ["b"]}]}`, []string{"a", "b"}, ""},
+		{`{"anyOf": [{"properties": {"a": {}, "b": {}}, "required": ["a", "b"]}, {"properties": {"b": {}}, "required": ["b"]}]}`, []string{"b"}, ""},
+		{`{"properties": {"a": {"$ref": "other.json#/a"}}}`, nil, "unsupported $ref"},
+		{`{"properties": {"a": {"$ref": "#/$defs/missing"}}}`, nil, "not found"},
+		{`{"properties": {"a": {"type": "date"}}}`, nil, "unknown type"},
+	}
+	for i, test := range tests {
+		def, order, err := decodeOrdered([]byte(test.schema))
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		typ, err := schemaType(def, order)
+		if err != nil {
+			if test.err == "" || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%d: got error %q want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		var required []string
+		for _, f := range typ.sortedFields() {
+			if f.count >= typ.objects {
+				required = append(required, f.key)
+			}
+		}
+		if strings.Join(required, ",") != strings.Join(test.required, ",") {
+			t.Errorf("%d: got required %q want %q", i, required, test.required)
+		}
+	}
+}
+
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		s        string
+		expected Input
+		err      bool
+	}{
+		{"samples", Samples, false},
+		{"schema", Schema, false},
+		{"xml", Samples, true},
+	}
+	for _, test := range tests {
+		in, err := ParseInput(test.s)
+		if (err != nil) != test.err {
+			t.Errorf("%s: got error %v, want error %t", test.s, err, test.err)
+			continue
+		}
+		if in != test.expected {
+			t.Errorf("%s: got %d want %d", test.s, in, test.expected)
+		}
+	}
+}
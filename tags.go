@@ -0,0 +1,199 @@
+package json2go
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// TagKey is an additional struct tag key, e.g. yaml or db, whose value is
+// derived from the field's JSON key.
+type TagKey struct {
+	// Key is the tag's key.
+	Key string
+	// Format transforms the JSON key into the tag's value.  If nil, the
+	// JSON key is used as is.
+	Format TagFormat
+	// Options are appended to the tag's value, e.g. omitempty results in
+	// `yaml:"name,omitempty"`.  Multiple options are comma separated.
+	Options string
+	// Template, if not nil, is executed with the field's TagField to
+	// create the tag's value, see ParseTagTemplate.  Format and Options
+	// are not used when there is a template.
+	Template *template.Template
+}
+
+// value returns the tag's value for the field.
+func (k TagKey) value(f TagField) (string, error) {
+	if k.Template != nil {
+		var buff bytes.Buffer
+		err := k.Template.Execute(&buff, f)
+		if err != nil {
+			return "", err
+		}
+		return buff.String(), nil
+	}
+	v := f.Key
+	if k.Format != nil {
+		v = k.Format(f.Key)
+	}
+	if k.Options != "" {
+		v += "," + k.Options
+	}
+	return v, nil
+}
+
+// TagFormat transforms a JSON key into the value of a struct tag.
+type TagFormat func(key string) string
+
+// tagFormats are the TagFormats that can be referred to by name.
+var tagFormats = map[string]TagFormat{
+	"snake":  SnakeCase,
+	"kebab":  KebabCase,
+	"camel":  CamelCase,
+	"pascal": PascalCase,
+	"lower":  strings.ToLower,
+	"upper":  strings.ToUpper,
+}
+
+// SnakeCase returns the key as lowercased words separated by underscores,
+// e.g. hOffset becomes h_offset.  Words are split the same way WordNamer
+// splits them.
+func SnakeCase(key string) string {
+	return strings.ToLower(strings.Join(splitWords(key), "_"))
+}
+
+// KebabCase returns the key as lowercased words separated by dashes, e.g.
+// hOffset becomes h-offset.
+func KebabCase(key string) string {
+	return strings.ToLower(strings.Join(splitWords(key), "-"))
+}
+
+// CamelCase returns the key in camelCase, e.g. h_offset becomes hOffset.
+func CamelCase(key string) string {
+	var s string
+	for i, w := range splitWords(key) {
+		w = strings.ToLower(w)
+		if i > 0 {
+			w = upperFirst(w)
+		}
+		s += w
+	}
+	return s
+}
+
+// PascalCase returns the key in PascalCase, e.g. h_offset becomes HOffset.
+func PascalCase(key string) string {
+	var s string
+	for _, w := range splitWords(key) {
+		s += upperFirst(strings.ToLower(w))
+	}
+	return s
+}
+
+// ParseTagKey parses a tag key definition in the form of key[:format][,opts],
+// e.g. yaml, db:snake, or xml:camel,attr.  The format, if any, must be one
+// of snake, kebab, camel, pascal, lower, or upper.
+func ParseTagKey(s string) (TagKey, error) {
+	var k TagKey
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, ","); i >= 0 {
+		s, k.Options = s[:i], s[i+1:]
+	}
+	if i := strings.Index(s, ":"); i >= 0 {
+		var format string
+		s, format = s[:i], s[i+1:]
+		f, ok := tagFormats[format]
+		if !ok {
+			return TagKey{}, fmt.Errorf("invalid tag key %q: unknown format %q", s, format)
+		}
+		k.Format = f
+	}
+	if s == "" || strings.ContainsAny(s, " \t\"`") {
+		return TagKey{}, fmt.Errorf("invalid tag key %q", s)
+	}
+	k.Key = s
+	return k, nil
+}
+
+// TagField is the data that a tag template is executed with.  It describes a
+// struct field and what was observed for its JSON key.
+type TagField struct {
+	// Key is the JSON key.
+	Key string
+	// Name is the name of the field.
+	Name string
+	// GoType is the field's Go type.
+	GoType string
+	// Optional is true if the key wasn't present in every sample.
+	Optional bool
+	// Nullable is true if a null was observed for the key.
+	Nullable bool
+	// HasRange is true if numbers were observed for the key; Min and Max
+	// are the smallest and largest of them.
+	HasRange bool
+	Min      float64
+	Max      float64
+	// HasLen is true if strings or arrays were observed for the key;
+	// MinLen and MaxLen are the shortest and longest of their lengths,
+	// in runes for strings.
+	HasLen bool
+	MinLen int
+	MaxLen int
+}
+
+// newTagField returns the TagField for a field of the parent object with the
+// name and Go type.
+func newTagField(f *field, parent *jsonType, name, goType string) TagField {
+	return TagField{
+		Key:      f.key,
+		Name:     name,
+		GoType:   goType,
+		Optional: f.count < parent.objects,
+		Nullable: f.typ.nulls > 0,
+		HasRange: f.typ.nums > 0,
+		Min:      f.typ.min,
+		Max:      f.typ.max,
+		HasLen:   f.typ.lens > 0,
+		MinLen:   f.typ.minLen,
+		MaxLen:   f.typ.maxLen,
+	}
+}
+
+// tagFuncs are the functions available to tag templates: each of the named
+// tag formats, e.g. {{snake .Key}}.
+var tagFuncs = func() template.FuncMap {
+	fm := make(template.FuncMap, len(tagFormats))
+	for k, f := range tagFormats {
+		fm[k] = f
+	}
+	return fm
+}()
+
+// ParseTagTemplate returns a TagKey whose value is the result of executing
+// the template, text, with the field's TagField.  The tag formats are
+// available as functions, e.g. `gorm:"column:{{snake .Key}}"` is:
+//
+//	ParseTagTemplate("gorm", "column:{{snake .Key}}")
+//
+// If the result is empty, the tag key is omitted for that field, e.g. only
+// fields that were always present get a `validate:"required"` tag with:
+//
+//	ParseTagTemplate("validate", "{{if not .Optional}}required{{end}}")
+func ParseTagTemplate(key, text string) (TagKey, error) {
+	if key == "" || strings.ContainsAny(key, " \t\"`:") {
+		return TagKey{}, fmt.Errorf("invalid tag key %q", key)
+	}
+	tmpl, err := template.New(key).Funcs(tagFuncs).Parse(text)
+	if err != nil {
+		return TagKey{}, err
+	}
+	// execute it once to catch references to things that don't exist
+	k := TagKey{Key: key, Template: tmpl}
+	_, err = k.value(TagField{Key: "key", Name: "Key", GoType: "string"})
+	if err != nil {
+		return TagKey{}, err
+	}
+	return k, nil
+}
@@ -0,0 +1,150 @@
+package json2go
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTagFormats(t *testing.T) {
+	tests := []struct {
+		key    string
+		snake  string
+		kebab  string
+		camel  string
+		pascal string
+	}{
+		{"hOffset", "h_offset", "h-offset", "hOffset", "HOffset"},
+		{"h_offset", "h_offset", "h-offset", "hOffset", "HOffset"},
+		{"HTTPServer", "http_server", "http-server", "httpServer", "HttpServer"},
+		{"user.name", "user_name", "user-name", "userName", "UserName"},
+		{"name", "name", "name", "name", "Name"},
+	}
+	for i, test := range tests {
+		if s := SnakeCase(test.key); s != test.snake {
+			t.Errorf("%d: SnakeCase(%q): got %q, want %q", i, test.key, s, test.snake)
+		}
+		if s := KebabCase(test.key); s != test.kebab {
+			t.Errorf("%d: KebabCase(%q): got %q, want %q", i, test.key, s, test.kebab)
+		}
+		if s := CamelCase(test.key); s != test.camel {
+			t.Errorf("%d: CamelCase(%q): got %q, want %q", i, test.key, s, test.camel)
+		}
+		if s := PascalCase(test.key); s != test.pascal {
+			t.Errorf("%d: PascalCase(%q): got %q, want %q", i, test.key, s, test.pascal)
+		}
+	}
+}
+
+func TestParseTagKey(t *testing.T) {
+	tests := []struct {
+		def     string
+		key     string
+		value   string
+		options string
+		err     bool
+	}{
+		{"yaml", "yaml", "hOffset", "", false},
+		{"db:snake", "db", "h_offset", "", false},
+		{"yaml:kebab,omitempty", "yaml", "h-offset", "omitempty", false},
+		{"xml:camel,attr", "xml", "hOffset", "attr", false},
+		{"bson,omitempty", "bson", "hOffset", "omitempty", false},
+		{"db:shouty", "", "", "", true},
+		{"", "", "", "", true},
+		{":snake", "", "", "", true},
+	}
+	for i, test := range tests {
+		k, err := ParseTagKey(test.def)
+		if (err != nil) != test.err {
+			t.Errorf("%d: ParseTagKey(%q): got error %v, want error %t", i, test.def, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if k.Key != test.key {
+			t.Errorf("%d: got key %q, want %q", i, k.Key, test.key)
+		}
+		if k.Options != test.options {
+			t.Errorf("%d: got options %q, want %q", i, k.Options, test.options)
+		}
+		v := "hOffset"
+		if k.Format != nil {
+			v = k.Format(v)
+		}
+		if v != test.value {
+			t.Errorf("%d: got value %q, want %q", i, v, test.value)
+		}
+	}
+}
+
+func TestSetTagKeys(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\tHOffset int `json:\"hOffset\" db:\"h_offset\" yaml:\"h-offset,omitempty\"`\n\tImage   `json:\"image\" db:\"image\" yaml:\"image,omitempty\"`\n}\n\ntype Image struct {\n\tSrc string `json:\"src\" db:\"src\" yaml:\"src,omitempty\"`\n}\n"
+	r := bytes.NewReader([]byte(`{"hOffset": 250, "image": {"src": "Images/Sun.png"}}`))
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	err := calvin.SetTagKeys([]string{"db:snake", "yaml:kebab,omitempty"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	err = calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+	if err := calvin.SetTagKeys([]string{"db:shouty"}); err == nil {
+		t.Error("expected an error, got none")
+	}
+}
+
+func TestTagTemplates(t *testing.T) {
+	expected := "package main\n\ntype Test struct {\n\tAge  int    `json:\"age\" validate:\"min=29,max=42\" bson:\"age,omitempty\"`\n\tID   int    `json:\"id\" validate:\"required\" bson:\"_id,omitempty\" gorm:\"column:id;type:int\"`\n\tName string `json:\"name\" validate:\"required,max=6\" bson:\"name,omitempty\" gorm:\"column:name;type:string\"`\n}\n"
+	r := bytes.NewReader([]byte(`[{"id": 1, "name": "Arthur", "age": 42}, {"id": 2, "name": "Ford", "age": 29}, {"id": 3, "name": "Zaphod"}]`))
+	var buff bytes.Buffer
+	calvin := NewTransmogrifier("test", r, &buff)
+	tmpls := []struct {
+		key  string
+		text string
+	}{
+		{"validate", `{{if not .Optional}}required{{if .HasLen}},{{end}}{{end}}{{if .HasLen}}max={{.MaxLen}}{{else if .Optional}}min={{.Min}},max={{.Max}}{{end}}`},
+		{"bson", `{{if eq .Key "id"}}_id{{else}}{{.Key}}{{end}},omitempty`},
+		{"gorm", `{{if not .Optional}}column:{{snake .Key}};type:{{.GoType}}{{end}}`},
+	}
+	for _, tmpl := range tmpls {
+		k, err := ParseTagTemplate(tmpl.key, tmpl.text)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", tmpl.key, err)
+		}
+		calvin.AddTagKey(k)
+	}
+	err := calvin.Gen()
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if buff.String() != expected {
+		t.Errorf("got %q want %q", buff.String(), expected)
+	}
+}
+
+func TestParseTagTemplate(t *testing.T) {
+	tests := []struct {
+		key  string
+		text string
+		err  bool
+	}{
+		{"validate", "required", false},
+		{"gorm", "column:{{snake .Key}}", false},
+		{"env", "{{upper .Key}}", false},
+		{"gorm", "{{.Column}}", true},
+		{"gorm", "{{shouty .Key}}", true},
+		{"gorm", "{{.Key", true},
+		{"", "{{.Key}}", true},
+	}
+	for i, test := range tests {
+		_, err := ParseTagTemplate(test.key, test.text)
+		if (err != nil) != test.err {
+			t.Errorf("%d: got error %v, want error %t", i, err, test.err)
+		}
+	}
+}
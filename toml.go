@@ -0,0 +1,525 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// decodeTOML decodes the TOML-encoded data, see toJSON; tables are objects.
+// Offset datetimes are strings, with the date and time separated by a T, so
+// that they are RFC 3339 timestamps.  Local dates, times, and datetimes are
+// errors: they aren't supported, as no type that they could be inferred as
+// can be decoded from both them and the JSON.  Floats that JSON can't
+// represent, e.g. inf, are strings.
+func decodeTOML(data []byte) (interface{}, error) {
+	p := tomlParser{s: strings.TrimPrefix(string(data), "\ufeff"), line: 1}
+	p.root = &orderedObject{}
+	p.cur = p.root
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return p.root, nil
+		}
+		var err error
+		if p.s[p.i] == '[' {
+			err = p.table()
+		} else {
+			err = p.keyValue(p.cur)
+		}
+		if err != nil {
+			return nil, err
+		}
+		err = p.endOfLine()
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// tomlParser parses a TOML document.
+type tomlParser struct {
+	s    string
+	i    int
+	line int
+	root *orderedObject
+	// cur is the table that key/value pairs are added to.
+	cur *orderedObject
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("toml: line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool {
+	return p.i >= len(p.s)
+}
+
+// skipBlank skips spaces and tabs and, if newlines is true, comments and
+// newlines.
+func (p *tomlParser) skipBlank(newlines bool) {
+	for !p.eof() {
+		switch c := p.s[p.i]; {
+		case c == ' ' || c == '\t':
+		case newlines && c == '\n':
+			p.line++
+		case newlines && c == '\r':
+		case newlines && c == '#':
+			for !p.eof() && p.s[p.i] != '\n' {
+				p.i++
+			}
+			continue
+		default:
+			return
+		}
+		p.i++
+	}
+}
+
+// endOfLine consumes the rest of the line, which may only be a comment.
+func (p *tomlParser) endOfLine() error {
+	p.skipBlank(false)
+	if p.eof() || p.s[p.i] == '\n' || p.s[p.i] == '#' || strings.HasPrefix(p.s[p.i:], "\r\n") {
+		return nil
+	}
+	return p.errorf("unexpected %q after value", p.s[p.i])
+}
+
+// table parses a table, [key], or array of tables, [[key]], header and makes
+// it the current table.
+func (p *tomlParser) table() error {
+	array := strings.HasPrefix(p.s[p.i:], "[[")
+	if array {
+		p.i += 2
+	} else {
+		p.i++
+	}
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	end := "]"
+	if array {
+		end = "]]"
+	}
+	if !strings.HasPrefix(p.s[p.i:], end) {
+		return p.errorf("expected %s after the table name", end)
+	}
+	p.i += len(end)
+	parent, err := p.descend(p.root, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	v, ok := parent.vals[last]
+	if array {
+		if !ok {
+			v = []interface{}{}
+		}
+		a, ok := v.([]interface{})
+		if !ok {
+			return p.errorf("%s is not an array of tables", strings.Join(keys, "."))
+		}
+		t := &orderedObject{}
+		parent.set(last, append(a, t))
+		p.cur = t
+		return nil
+	}
+	if !ok {
+		v = &orderedObject{}
+		parent.set(last, v)
+	}
+	t, ok := v.(*orderedObject)
+	if !ok {
+		return p.errorf("%s is not a table", strings.Join(keys, "."))
+	}
+	p.cur = t
+	return nil
+}
+
+// descend returns the table that is reached by following keys from t; tables
+// that don't exist are created.  If a key is an array of tables, its last
+// table is followed.
+func (p *tomlParser) descend(t *orderedObject, keys []string) (*orderedObject, error) {
+	for i, k := range keys {
+		v, ok := t.vals[k]
+		if !ok {
+			v = &orderedObject{}
+			t.set(k, v)
+		}
+		if a, ok := v.([]interface{}); ok && len(a) > 0 {
+			v = a[len(a)-1]
+		}
+		next, ok := v.(*orderedObject)
+		if !ok {
+			return nil, p.errorf("%s is not a table", strings.Join(keys[:i+1], "."))
+		}
+		t = next
+	}
+	return t, nil
+}
+
+// keyValue parses a key = value pair and adds it to t.
+func (p *tomlParser) keyValue(t *orderedObject) error {
+	keys, err := p.key()
+	if err != nil {
+		return err
+	}
+	if p.eof() || p.s[p.i] != '=' {
+		return p.errorf("expected = after the key")
+	}
+	p.i++
+	p.skipBlank(false)
+	v, err := p.value()
+	if err != nil {
+		return err
+	}
+	t, err = p.descend(t, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	last := keys[len(keys)-1]
+	if _, ok := t.vals[last]; ok {
+		return p.errorf("duplicate key %q", strings.Join(keys, "."))
+	}
+	t.set(last, v)
+	return nil
+}
+
+// key parses a, possibly dotted, key.
+func (p *tomlParser) key() ([]string, error) {
+	var keys []string
+	for {
+		p.skipBlank(false)
+		if p.eof() {
+			return nil, p.errorf("expected a key")
+		}
+		var k string
+		switch p.s[p.i] {
+		case '"', '\'':
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			k = v.(string)
+		default:
+			start := p.i
+			for !p.eof() && isTOMLBareKey(p.s[p.i]) {
+				p.i++
+			}
+			if start == p.i {
+				return nil, p.errorf("invalid key character %q", p.s[p.i])
+			}
+			k = p.s[start:p.i]
+		}
+		keys = append(keys, k)
+		p.skipBlank(false)
+		if p.eof() || p.s[p.i] != '.' {
+			return keys, nil
+		}
+		p.i++
+	}
+}
+
+func isTOMLBareKey(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// value parses the value at the current position.
+func (p *tomlParser) value() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("expected a value")
+	}
+	switch {
+	case strings.HasPrefix(p.s[p.i:], `"""`):
+		return p.multiLineString(`"""`)
+	case strings.HasPrefix(p.s[p.i:], "'''"):
+		return p.multiLineString("'''")
+	case p.s[p.i] == '"':
+		p.i++
+		start := p.i
+		for !p.eof() && p.s[p.i] != '"' && p.s[p.i] != '\n' {
+			if p.s[p.i] == '\\' {
+				p.i++
+			}
+			p.i++
+		}
+		if p.eof() || p.s[p.i] != '"' {
+			return nil, p.errorf("unterminated string")
+		}
+		p.i++
+		return p.unescape(p.s[start : p.i-1])
+	case p.s[p.i] == '\'':
+		p.i++
+		end := strings.IndexAny(p.s[p.i:], "'\n")
+		if end < 0 || p.s[p.i+end] != '\'' {
+			return nil, p.errorf("unterminated string")
+		}
+		v := p.s[p.i : p.i+end]
+		p.i += end + 1
+		return v, nil
+	case p.s[p.i] == '[':
+		return p.array()
+	case p.s[p.i] == '{':
+		return p.inlineTable()
+	}
+	// a bare value ends at the end of the line, a comment, or the end of
+	// the array or inline table it is in
+	start := p.i
+	for !p.eof() && strings.IndexByte(",]}#\r\n", p.s[p.i]) < 0 {
+		p.i++
+	}
+	tok := strings.TrimRight(p.s[start:p.i], " \t")
+	p.i = start + len(tok)
+	if isTOMLDateTime(tok) && !isTOMLOffsetDateTime(tok) {
+		return nil, p.errorf("%q isn't an offset datetime: local dates, times, and datetimes aren't supported", tok)
+	}
+	v, ok := tomlScalar(tok)
+	if !ok {
+		return nil, p.errorf("invalid value %q", tok)
+	}
+	return v, nil
+}
+
+// multiLineString parses the multi-line string delimited by delim.
+func (p *tomlParser) multiLineString(delim string) (interface{}, error) {
+	p.i += 3
+	// a newline immediately following the opening delimiter is trimmed
+	if strings.HasPrefix(p.s[p.i:], "\r\n") {
+		p.i += 2
+		p.line++
+	} else if strings.HasPrefix(p.s[p.i:], "\n") {
+		p.i++
+		p.line++
+	}
+	start := p.i
+	for {
+		if p.eof() {
+			return nil, p.errorf("unterminated multi-line string")
+		}
+		if delim == `"""` && p.s[p.i] == '\\' {
+			p.i += 2
+			continue
+		}
+		if strings.HasPrefix(p.s[p.i:], delim) {
+			break
+		}
+		if p.s[p.i] == '\n' {
+			p.line++
+		}
+		p.i++
+	}
+	// up to two quotes may be at the end of the string's content
+	end := p.i
+	for n := 0; n < 2 && strings.HasPrefix(p.s[end+1:], delim); n++ {
+		end++
+	}
+	v := p.s[start:end]
+	p.i = end + 3
+	if delim == "'''" {
+		return v, nil
+	}
+	return p.unescape(v)
+}
+
+// unescape returns the value of the basic string s, whose escape sequences
+// are replaced with what they encode.
+func (p *tomlParser) unescape(s string) (interface{}, error) {
+	if strings.IndexByte(s, '\\') < 0 {
+		return s, nil
+	}
+	var buff strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			buff.WriteByte(c)
+			continue
+		}
+		i++
+		if i == len(s) {
+			return nil, p.errorf("invalid escape at the end of a string")
+		}
+		switch s[i] {
+		case 'b':
+			buff.WriteByte('\b')
+		case 't':
+			buff.WriteByte('\t')
+		case 'n':
+			buff.WriteByte('\n')
+		case 'f':
+			buff.WriteByte('\f')
+		case 'r':
+			buff.WriteByte('\r')
+		case 'e':
+			buff.WriteByte('\x1b')
+		case '"':
+			buff.WriteByte('"')
+		case '\\':
+			buff.WriteByte('\\')
+		case 'u', 'U':
+			n := 4
+			if s[i] == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return nil, p.errorf("invalid escape \\%s", s[i:])
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(r)) {
+				return nil, p.errorf("invalid escape \\%s", s[i:i+1+n])
+			}
+			buff.WriteRune(rune(r))
+			i += n
+		case ' ', '\t', '\r', '\n':
+			// a line ending backslash trims all of the whitespace,
+			// including newlines, up to the next non-whitespace
+			j := i
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t' || s[j] == '\r') {
+				j++
+			}
+			if j == len(s) || s[j] != '\n' {
+				return nil, p.errorf("invalid escape \\%c", s[i])
+			}
+			for j < len(s) && strings.IndexByte(" \t\r\n", s[j]) >= 0 {
+				j++
+			}
+			i = j - 1
+		default:
+			return nil, p.errorf("invalid escape \\%c", s[i])
+		}
+	}
+	return buff.String(), nil
+}
+
+// array parses an array; newlines and comments may be between its values.
+func (p *tomlParser) array() (interface{}, error) {
+	p.i++
+	a := []interface{}{}
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		if p.s[p.i] == ']' {
+			p.i++
+			return a, nil
+		}
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated array")
+		}
+		switch p.s[p.i] {
+		case ',':
+			p.i++
+		case ']':
+		default:
+			return nil, p.errorf("expected , or ] in array, got %q", p.s[p.i])
+		}
+	}
+}
+
+// inlineTable parses an inline table.
+func (p *tomlParser) inlineTable() (interface{}, error) {
+	p.i++
+	t := &orderedObject{}
+	for {
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		if p.s[p.i] == '}' {
+			p.i++
+			return t, nil
+		}
+		err := p.keyValue(t)
+		if err != nil {
+			return nil, err
+		}
+		p.skipBlank(true)
+		if p.eof() {
+			return nil, p.errorf("unterminated inline table")
+		}
+		switch p.s[p.i] {
+		case ',':
+			p.i++
+		case '}':
+		default:
+			return nil, p.errorf("expected , or } in inline table, got %q", p.s[p.i])
+		}
+	}
+}
+
+// tomlScalar returns the value of the bare value, tok: a bool, number, or
+// date and time.
+func tomlScalar(tok string) (interface{}, bool) {
+	switch tok {
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	case "inf", "+inf", "-inf", "nan", "+nan", "-nan":
+		return tok, true
+	}
+	if isTOMLDateTime(tok) {
+		if len(tok) > 10 && tok[10] == ' ' {
+			tok = tok[:10] + "T" + tok[11:]
+		}
+		return tok, true
+	}
+	if tok == "" || strings.Contains(tok, "__") || strings.HasPrefix(tok, "_") || strings.HasSuffix(tok, "_") {
+		return nil, false
+	}
+	s := strings.Replace(tok, "_", "", -1)
+	for _, base := range []struct {
+		prefix string
+		base   int
+	}{{"0x", 16}, {"0o", 8}, {"0b", 2}} {
+		if strings.HasPrefix(s, base.prefix) {
+			v, err := strconv.ParseInt(s[2:], base.base, 64)
+			if err != nil {
+				return nil, false
+			}
+			return json.Number(strconv.FormatInt(v, 10)), true
+		}
+	}
+	n, ok := jsonNumber(s)
+	if !ok {
+		return nil, false
+	}
+	return n, true
+}
+
+// isTOMLDateTime returns whether s is a TOML date, time, or date and time.
+// Only its shape is checked: a date starts with yyyy-, a time with hh:.
+func isTOMLDateTime(s string) bool {
+	digits := func(s string, n int) bool {
+		if len(s) < n {
+			return false
+		}
+		for i := 0; i < n; i++ {
+			if s[i] < '0' || s[i] > '9' {
+				return false
+			}
+		}
+		return true
+	}
+	return digits(s, 4) && len(s) > 4 && s[4] == '-' || digits(s, 2) && len(s) > 2 && s[2] == ':'
+}
+
+// isTOMLOffsetDateTime returns whether s is an offset datetime, which is an
+// RFC 3339 timestamp once its date and time are separated by a T.
+func isTOMLOffsetDateTime(s string) bool {
+	if len(s) > 10 && s[10] == ' ' {
+		s = s[:10] + "T" + s[11:]
+	}
+	_, err := time.Parse(time.RFC3339Nano, s)
+	return err == nil
+}
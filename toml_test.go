@@ -0,0 +1,55 @@
+package json2go
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	tests := []struct {
+		toml     string
+		expected string
+		err      string
+	}{
+		{"b = 1 # comment\na = \"x\"\n", `{"b":1,"a":"x"}`, ""},
+		{"a = 1_000\nb = 0xff\nc = 0o17\nd = 0b11\ne = +1.5\nf = 6e-3\ng = -inf\n", `{"a":1000,"b":255,"c":15,"d":3,"e":1.5,"f":6e-3,"g":"-inf"}`, ""},
+		{"a = 1979-05-27 07:32:00Z\nb = 1979-05-27T00:32:00.999999-07:00\n", `{"a":"1979-05-27T07:32:00Z","b":"1979-05-27T00:32:00.999999-07:00"}`, ""},
+		{"a = \"tab\\t\\u00e9\"\nb = 'C:\\x'\nc = \"\"\"\nx \\\n  y\"\"\"\nd = '''\nl\nm'''\n", `{"a":"tab\té","b":"C:\\x","c":"x y","d":"l\nm"}`, ""},
+		{"a = [\n  1,\n  2, # two\n]\nb = { x = 1, y.z = true }\n", `{"a":[1,2],"b":{"x":1,"y":{"z":true}}}`, ""},
+		{"a.b = 1\n\"c.d\" = 2\n", `{"a":{"b":1},"c.d":2}`, ""},
+		{"[server]\nhost = \"x\"\n[server.tls]\non = true\n[db]\nport = 1\n", `{"server":{"host":"x","tls":{"on":true}},"db":{"port":1}}`, ""},
+		{"[[p]]\nn = 1\n[[p]]\nn = 2\n[p.d]\nw = 3\n", `{"p":[{"n":1},{"n":2,"d":{"w":3}}]}`, ""},
+		{"a = 1\na = 2\n", "", "line 2: duplicate key"},
+		{"a = \"x\n", "", "line 1: unterminated string"},
+		{"a = 1 2\n", "", "invalid value"},
+		{"\n[a\n", "", "line 2: expected ]"},
+		{"a = 1\n[a]\n", "", "a is not a table"},
+		{"a = [1, 2\n", "", "unterminated array"},
+		{"a = \"\\q\"\n", "", "invalid escape"},
+		{"a = 1979-05-27\n", "", `line 1: "1979-05-27" isn't an offset datetime`},
+		{"a = [07:32:00]\n", "", `"07:32:00" isn't an offset datetime`},
+		{"a = 1979-05-27T07:32:00\n", "", "local dates, times, and datetimes aren't supported"},
+	}
+	for i, test := range tests {
+		v, err := decodeTOML([]byte(test.toml))
+		if err != nil {
+			if test.err == "" || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%d: got error %q, want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%d: got %s want %s", i, b, test.expected)
+		}
+	}
+}
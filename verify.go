@@ -0,0 +1,373 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VerifyError is returned by Gen, when Verify is set, if the generated code
+// doesn't type-check or any of the samples wouldn't decode into the generated
+// types.  Nothing is written when verification fails.
+type VerifyError struct {
+	// Problems describes each type error and each value that wouldn't
+	// decode.
+	Problems []string
+}
+
+func (e VerifyError) Error() string {
+	return fmt.Sprintf("verification failed:\n\t%s", strings.Join(e.Problems, "\n\t"))
+}
+
+// verify type-checks the generated source, src, if it is Go, and checks that every sample
+// in the source JSON, data, decodes into the model's types.  If the input is a
+// HAR, the responses of each of its endpoints are the samples of their
+// endpoint's type instead.
+func (t *Transmogrifier) verify(src, data []byte, m Model, endpoints []harRoot) error {
+	var problems []string
+	if t.Format == GoSource {
+		problems = typeCheck(t.pkg, src)
+	}
+	if t.From == HAR {
+		problems = append(problems, t.verifyEndpoints(m, endpoints)...)
+		if len(problems) > 0 {
+			return VerifyError{Problems: problems}
+		}
+		return nil
+	}
+	if t.From != Samples {
+		// there are no samples to check
+		if len(problems) > 0 {
+			return VerifyError{Problems: problems}
+		}
+		return nil
+	}
+	// numbers are decoded as json.Number so that their literal text can be
+	// checked, e.g. 1.0 doesn't decode into an int
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var def interface{}
+	err := dec.Decode(&def)
+	if err != nil {
+		return err
+	}
+	v := verifier{types: make(map[string]TypeDef, len(m.Types)), strict: t.StrictEnums}
+	for _, td := range m.Types {
+		v.types[td.Name] = td
+	}
+	samples, _ := getSamples(def, nil)
+	for i, sample := range samples {
+		v.sample = i + 1
+		v.check("$", m.Types[0].Name, "", sample)
+	}
+	problems = append(problems, v.problems...)
+	if len(problems) > 0 {
+		return VerifyError{Problems: problems}
+	}
+	return nil
+}
+
+// verifyEndpoints returns the problems with decoding the responses of each
+// HAR endpoint into the endpoint's type.
+func (t *Transmogrifier) verifyEndpoints(m Model, endpoints []harRoot) []string {
+	v := verifier{types: make(map[string]TypeDef, len(m.Types)), strict: t.StrictEnums}
+	for _, td := range m.Types {
+		v.types[td.Name] = td
+	}
+	for _, e := range endpoints {
+		v.endpoint = e.endpoint
+		for i, body := range e.bodies {
+			v.sample = i + 1
+			dec := json.NewDecoder(bytes.NewReader(body))
+			dec.UseNumber()
+			var val interface{}
+			err := dec.Decode(&val)
+			if err != nil {
+				v.problem("$", "%s", err)
+				continue
+			}
+			v.check("$", e.name, "", val)
+		}
+	}
+	return v.problems
+}
+
+// typeCheck returns the errors that result from type-checking the source.
+func typeCheck(pkg string, src []byte) []string {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "generated.go", src, 0)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	var problems []string
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error: func(err error) {
+			problems = append(problems, err.Error())
+		},
+	}
+	conf.Check(pkg, fset, []*ast.File{f}, nil)
+	return problems
+}
+
+// verifier checks decoded JSON values against the generated types.
+type verifier struct {
+	types map[string]TypeDef
+	// strict is whether enums reject values that weren't observed.
+	strict bool
+	// sample is the number of the sample being checked, starting at 1.
+	sample int
+	// endpoint is the HAR endpoint, e.g. GET /users, whose responses are
+	// the samples being checked, if any.
+	endpoint string
+	// args are the type arguments of the generic type being checked, by
+	// the name of their type parameter.
+	args     map[string]string
+	problems []string
+}
+
+// check checks that val, at the JSON path, decodes into the Go type.  If opt
+// is string, the value is string-encoded.
+func (v *verifier) check(path, goType, opt string, val interface{}) {
+	if val == nil {
+		// decoding a null is a no-op, unless the type is a named one,
+		// which may implement json.Unmarshaler
+		if _, ok := v.types[goType]; ok {
+			v.checkNamed(path, goType, val)
+		}
+		return
+	}
+	if opt == "string" {
+		s, ok := val.(string)
+		if !ok {
+			v.mismatch(path, goType, val)
+			return
+		}
+		val = json.Number(s)
+		if goType == "bool" {
+			if s != "true" && s != "false" {
+				v.mismatch(path, goType, val)
+			}
+			return
+		}
+	}
+	switch {
+	case goType == "interface{}":
+	case goType == "bool":
+		if _, ok := val.(bool); !ok {
+			v.mismatch(path, goType, val)
+		}
+	case goType == "int", goType == "int64":
+		n, ok := val.(json.Number)
+		if !ok {
+			v.mismatch(path, goType, val)
+		} else if _, err := strconv.ParseInt(n.String(), 10, 64); err != nil {
+			v.mismatch(path, goType, val)
+		}
+	case goType == "float64":
+		n, ok := val.(json.Number)
+		if !ok {
+			v.mismatch(path, goType, val)
+		} else if _, err := n.Float64(); err != nil {
+			v.mismatch(path, goType, val)
+		}
+	case goType == "string":
+		if _, ok := val.(string); !ok {
+			v.mismatch(path, goType, val)
+		}
+	case goType == "time.Time":
+		// time.Time's UnmarshalJSON requires an RFC 3339 timestamp
+		s, ok := val.(string)
+		if !ok {
+			v.mismatch(path, goType, val)
+		} else if _, err := time.Parse(time.RFC3339, s); err != nil {
+			v.problem(path, "cannot decode %q into %s", s, goType)
+		}
+	case strings.HasPrefix(goType, "*"):
+		v.check(path, goType[1:], "", val)
+	case strings.HasPrefix(goType, "[]"):
+		a, ok := val.([]interface{})
+		if !ok {
+			v.mismatch(path, goType, val)
+			return
+		}
+		for i, e := range a {
+			v.check(fmt.Sprintf("%s[%d]", path, i), goType[2:], "", e)
+		}
+	case strings.HasPrefix(goType, "map[string]"):
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			v.mismatch(path, goType, val)
+			return
+		}
+		for k, e := range m {
+			v.check(jsonPath(path, k), goType[len("map[string]"):], "", e)
+		}
+	default:
+		v.checkNamed(path, goType, val)
+	}
+}
+
+// checkNamed checks that val decodes into the named type.
+func (v *verifier) checkNamed(path, name string, val interface{}) {
+	if arg, ok := v.args[name]; ok {
+		v.check(path, arg, "", val)
+		return
+	}
+	td, ok := v.types[name]
+	if !ok {
+		v.problem(path, "undefined type %s", name)
+		return
+	}
+	switch td.Kind {
+	case "map", "slice", "scalar":
+		v.check(path, td.Type, "", val)
+	case "alias":
+		v.checkAlias(path, td.Type, val)
+	case "enum":
+		// this mirrors the UnmarshalJSON method of strict enums, see
+		// defineEnum, which, like decoding into a string, ignores null
+		if val == nil {
+			return
+		}
+		s, ok := val.(string)
+		if !ok {
+			v.mismatch(path, name, val)
+			return
+		}
+		if !v.strict {
+			return
+		}
+		for _, ev := range td.Values {
+			if ev.Value == s {
+				return
+			}
+		}
+		v.problem(path, "%q is not a valid %s", s, name)
+	case "struct":
+		if val == nil {
+			return
+		}
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			v.mismatch(path, name, val)
+			return
+		}
+		for _, k := range sortedKeys(m) {
+			f, ok := fieldFor(td.Fields, k)
+			if !ok {
+				v.problem(jsonPath(path, k), "unknown field in %s", name)
+				continue
+			}
+			v.check(jsonPath(path, k), f.GoType, tagOption(f.Tag), m[k])
+		}
+	default:
+		v.problem(path, "can't verify %s, which is a %s", name, td.Kind)
+	}
+}
+
+// checkAlias checks that val decodes into the aliased type, which may be an
+// instance of a generic type, e.g. Page[User].
+func (v *verifier) checkAlias(path, typ string, val interface{}) {
+	i := strings.Index(typ, "[")
+	if i < 0 || !strings.HasSuffix(typ, "]") {
+		v.check(path, typ, "", val)
+		return
+	}
+	name := typ[:i]
+	args := strings.Split(typ[i+1:len(typ)-1], ",")
+	params := strings.Split(v.types[name].TypeParams, ",")
+	if v.types[name].TypeParams == "" || len(params) != len(args) {
+		v.problem(path, "wrong number of type arguments for %s", typ)
+		return
+	}
+	saved := v.args
+	v.args = make(map[string]string, len(params))
+	for j, p := range params {
+		v.args[strings.Fields(p)[0]] = strings.TrimSpace(args[j])
+	}
+	v.checkNamed(path, name, val)
+	v.args = saved
+}
+
+// mismatch records that the value at path can't be decoded into the Go type.
+func (v *verifier) mismatch(path, goType string, val interface{}) {
+	var s string
+	switch val := val.(type) {
+	case bool:
+		s = "bool"
+	case json.Number:
+		s = "number " + val.String()
+	case string:
+		s = "string"
+	case []interface{}:
+		s = "array"
+	case map[string]interface{}:
+		s = "object"
+	}
+	v.problem(path, "cannot decode %s into %s", s, goType)
+}
+
+// problem records a problem with the value at path in the sample being
+// checked.
+func (v *verifier) problem(path, format string, args ...interface{}) {
+	sample := fmt.Sprintf("sample %d", v.sample)
+	if v.endpoint != "" {
+		sample = fmt.Sprintf("%s: response %d", v.endpoint, v.sample)
+	}
+	v.problems = append(v.problems, fmt.Sprintf("%s: %s: %s", sample, path, fmt.Sprintf(format, args...)))
+}
+
+// jsonName returns the name that encoding/json decodes the field from, as
+// it reads the field's json tag: the name is what precedes the first comma,
+// unless it is empty or invalid, see isJSONTagName, in which case it is the
+// field's name.  False is returned if the field is skipped, `json:"-"`.
+func jsonName(f FieldDef) (string, bool) {
+	tag := reflect.StructTag(strings.Trim(f.Tag, "`")).Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	if i := strings.Index(tag, ","); i >= 0 {
+		tag = tag[:i]
+	}
+	if !isJSONTagName(tag) {
+		return f.Name, true
+	}
+	return tag, true
+}
+
+// fieldFor returns the field that encoding/json decodes the key into: the
+// one with that name or, failing that, the first one whose name matches the
+// key case insensitively.
+func fieldFor(fields []FieldDef, key string) (FieldDef, bool) {
+	for _, f := range fields {
+		if k, ok := jsonName(f); ok && k == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if k, ok := jsonName(f); ok && strings.EqualFold(k, key) {
+			return f, true
+		}
+	}
+	return FieldDef{}, false
+}
+
+// tagOption returns the option of the json tag, if any, e.g. string for
+// `json:"id,string"`.
+func tagOption(tag string) string {
+	v := reflect.StructTag(strings.Trim(tag, "`")).Get("json")
+	if i := strings.Index(v, ","); i >= 0 {
+		return v[i+1:]
+	}
+	return ""
+}
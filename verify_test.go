@@ -0,0 +1,156 @@
+package json2go
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		json     string
+		tmpl     string
+		problems []string
+	}{
+		{`{"id": 1, "name": "towel", "tags": ["soft"], "owner": {"id": 42}}`, "", nil},
+		{`[{"id": "1", "size": 1.5, "ok": "true"}, {"id": "2", "size": 2, "ok": "false"}]`, "", nil},
+		{`[{"n": 1}, {"n": 1.0}]`, "", nil},
+		{`{"id": 1}`, "package main\n{{range .Types}}{{.Source}}\n{{.Source}}\n{{end}}", []string{"Test redeclared"}},
+	}
+	for i, test := range tests {
+		var buff, jsn bytes.Buffer
+		calvin := NewTransmogrifier("test", strings.NewReader(test.json), &buff)
+		calvin.Verify = true
+		calvin.StringEncoded = true
+		calvin.WriteJSON = true
+		calvin.SetJSONWriter(&jsn)
+		if test.tmpl != "" {
+			err := calvin.SetTemplate(test.tmpl)
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+				continue
+			}
+		}
+		err := calvin.Gen()
+		if test.problems == nil {
+			if err != nil {
+				t.Errorf("%d: unexpected error: %s", i, err)
+			}
+			continue
+		}
+		verr, ok := err.(VerifyError)
+		if !ok {
+			t.Errorf("%d: expected a VerifyError, got %v", i, err)
+			continue
+		}
+		if len(verr.Problems) == 0 || !strings.Contains(verr.Problems[0], test.problems[0]) {
+			t.Errorf("%d: got %q want %q", i, verr.Problems, test.problems)
+		}
+		if buff.Len() != 0 || jsn.Len() != 0 {
+			t.Errorf("%d: expected nothing to be written, got %q and %q", i, buff.String(), jsn.String())
+		}
+	}
+}
+
+func TestVerifier(t *testing.T) {
+	types := []TypeDef{
+		{Name: "Things", Kind: "map", Type: "map[string][]Thing"},
+		{Name: "Thing", Kind: "struct", Fields: []FieldDef{
+			{TagField: TagField{Key: "id", GoType: "int64"}, Tag: "`json:\"id,string\"`"},
+			{TagField: TagField{Key: "color", GoType: "Color"}, Tag: "`json:\"color\"`"},
+			{TagField: TagField{Key: "parent", GoType: "*Thing"}, Tag: "`json:\"parent\"`"},
+			{TagField: TagField{Key: "at", GoType: "time.Time"}, Tag: "`json:\"at\"`"},
+		}},
+		{Name: "Color", Kind: "enum", Type: "string", Values: []EnumValue{{Name: "ColorRed", Value: "red"}}},
+	}
+	tests := []struct {
+		val      interface{}
+		strict   bool
+		expected []string
+	}{
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"id": "1", "color": "red", "parent": nil}}}, true, nil},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"color": "blue"}}}, false, nil},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"color": nil}}}, true, nil},
+		{map[string]interface{}{"a": nil, "b": []interface{}{nil}}, true, nil},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"color": "blue"}}}, true, []string{`sample 1: $.a[0].color: "blue" is not a valid Color`}},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"id": "x", "size": 1}}}, false, []string{"sample 1: $.a[0].id: cannot decode number x into int64", "sample 1: $.a[0].size: unknown field in Thing"}},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"parent": map[string]interface{}{"id": true}}}}, false, []string{"sample 1: $.a[0].parent.id: cannot decode bool into int64"}},
+		{map[string]interface{}{"a": map[string]interface{}{}}, false, []string{"sample 1: $.a: cannot decode object into []Thing"}},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"at": "1979-05-27T07:32:00-08:00"}}}, false, nil},
+		{map[string]interface{}{"a": []interface{}{map[string]interface{}{"at": "1979-05-27"}}}, false, []string{`sample 1: $.a[0].at: cannot decode "1979-05-27" into time.Time`}},
+	}
+	for i, test := range tests {
+		v := verifier{types: map[string]TypeDef{}, strict: test.strict, sample: 1}
+		for _, td := range types {
+			v.types[td.Name] = td
+		}
+		v.check("$", "Things", "", test.val)
+		if !reflect.DeepEqual(v.problems, test.expected) {
+			t.Errorf("%d: got %q want %q", i, v.problems, test.expected)
+		}
+	}
+}
+
+func TestVerifierAlias(t *testing.T) {
+	types := []TypeDef{
+		{Name: "Users", Kind: "alias", Type: "Page[User]"},
+		{Name: "Page", Kind: "struct", TypeParams: "T any", Fields: []FieldDef{
+			{TagField: TagField{Key: "data", GoType: "[]T"}, Tag: "`json:\"data\"`"},
+			{TagField: TagField{Key: "total", GoType: "int"}, Tag: "`json:\"total\"`"},
+		}},
+		{Name: "User", Kind: "struct", Fields: []FieldDef{
+			{TagField: TagField{Key: "id", GoType: "int"}, Tag: "`json:\"id\"`"},
+		}},
+	}
+	tests := []struct {
+		val      interface{}
+		expected []string
+	}{
+		{map[string]interface{}{"data": []interface{}{map[string]interface{}{"id": json.Number("1")}}, "total": json.Number("1")}, nil},
+		{map[string]interface{}{"data": []interface{}{map[string]interface{}{"id": "1", "name": "ann"}}}, []string{"sample 1: $.data[0].id: cannot decode string into int", "sample 1: $.data[0].name: unknown field in User"}},
+	}
+	for i, test := range tests {
+		v := verifier{types: map[string]TypeDef{}, sample: 1}
+		for _, td := range types {
+			v.types[td.Name] = td
+		}
+		v.check("$", "Users", "", test.val)
+		if !reflect.DeepEqual(v.problems, test.expected) {
+			t.Errorf("%d: got %q want %q", i, v.problems, test.expected)
+		}
+	}
+}
+
+// TestVerifierTags tests that keys are matched to fields the way that
+// encoding/json matches them, by the name in the json tag.
+func TestVerifierTags(t *testing.T) {
+	thing := TypeDef{Name: "Thing", Kind: "struct", Fields: []FieldDef{
+		{TagField: TagField{Key: "-", Name: "Skipped", GoType: "int"}, Tag: "`json:\"-\"`"},
+		{TagField: TagField{Key: "foo,bar", Name: "Foo", GoType: "int"}, Tag: "`json:\"foo,bar\"`"},
+		{TagField: TagField{Key: "q\"x", Name: "Q", GoType: "int"}, Tag: "`json:\"q\\\"x\"`"},
+		{TagField: TagField{Key: "name", Name: "Name", GoType: "string"}, Tag: "`json:\"name\"`"},
+	}}
+	dash := TypeDef{Name: "Thing", Kind: "struct", Fields: []FieldDef{
+		{TagField: TagField{Key: "-", Name: "Dash", GoType: "int"}, Tag: "`json:\"-,\"`"},
+	}}
+	tests := []struct {
+		def      TypeDef
+		val      map[string]interface{}
+		expected []string
+	}{
+		{thing, map[string]interface{}{"-": json.Number("1")}, []string{`sample 1: $["-"]: unknown field in Thing`}},
+		{thing, map[string]interface{}{"foo,bar": json.Number("1"), "foo": json.Number("1")}, []string{`sample 1: $["foo,bar"]: unknown field in Thing`}},
+		{thing, map[string]interface{}{"q\"x": json.Number("1"), "q": json.Number("1")}, []string{`sample 1: $["q\"x"]: unknown field in Thing`}},
+		{thing, map[string]interface{}{"NAME": "ann"}, nil},
+		{dash, map[string]interface{}{"-": json.Number("1")}, nil},
+	}
+	for i, test := range tests {
+		v := verifier{types: map[string]TypeDef{"Thing": test.def}, sample: 1}
+		v.check("$", "Thing", "", test.val)
+		if !reflect.DeepEqual(v.problems, test.expected) {
+			t.Errorf("%d: got %q want %q", i, v.problems, test.expected)
+		}
+	}
+}
@@ -0,0 +1,856 @@
+package json2go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// decodeYAML decodes the YAML-encoded data, see toJSON.  If the data has more
+// than one document, the result is an array of the documents, which are
+// treated as samples.
+//
+// The YAML that is used for configuration is supported: block and flow
+// mappings and sequences, plain, quoted, and block scalars, comments,
+// anchors, aliases, merge keys, and the core schema's tags, e.g. !!str and
+// !!int.  Complex keys, quoted scalars that span lines, and other tags are
+// not: they are errors rather than being read as something else.
+func decodeYAML(data []byte) (interface{}, error) {
+	p := yamlParser{anchors: map[string]interface{}{}}
+	var docs []interface{}
+	for _, lines := range splitYAMLDocs(data) {
+		p.lines, p.pos = lines, 0
+		v, err := p.parseNode(0)
+		if err != nil {
+			return nil, err
+		}
+		if l := p.peek(); l != nil {
+			return nil, l.errorf("unexpected content")
+		}
+		docs = append(docs, v)
+	}
+	switch len(docs) {
+	case 0:
+		return nil, fmt.Errorf("yaml: no documents")
+	case 1:
+		return docs[0], nil
+	}
+	return docs, nil
+}
+
+// yamlLine is a line of a YAML document.
+type yamlLine struct {
+	// num is the line number, starting at 1.
+	num int
+	// indent is the number of spaces the line is indented by.
+	indent int
+	// text is the line without its indentation, trailing whitespace, or
+	// comment; it is empty for blank lines.
+	text string
+	// raw is the line as is, which is used for block scalars.
+	raw string
+}
+
+func (l *yamlLine) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("yaml: line %d: %s", l.num, fmt.Sprintf(format, args...))
+}
+
+// splitYAMLDocs splits the data into the lines of each of its documents.
+// Directives and document markers aren't part of any document.
+func splitYAMLDocs(data []byte) [][]yamlLine {
+	var docs [][]yamlLine
+	var doc []yamlLine
+	var content bool
+	for i, raw := range strings.Split(string(data), "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		if i == 0 {
+			raw = strings.TrimPrefix(raw, "\ufeff")
+		}
+		switch {
+		case strings.HasPrefix(raw, "%") && !content:
+			continue
+		case raw == "---" || strings.HasPrefix(raw, "--- "):
+			if content {
+				docs = append(docs, doc)
+			}
+			doc, content = nil, false
+			raw = "   " + raw[3:]
+		case raw == "...":
+			if content {
+				docs = append(docs, doc)
+			}
+			doc, content = nil, false
+			continue
+		}
+		l := yamlLine{num: i + 1, raw: raw}
+		text := strings.TrimLeft(raw, " ")
+		l.indent = len(raw) - len(text)
+		l.text = strings.TrimRight(stripYAMLComment(text), " \t")
+		if l.text != "" {
+			content = true
+		}
+		doc = append(doc, l)
+	}
+	if content {
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// stripYAMLComment removes the comment, if any, from s.  A comment starts
+// with a # that is at the start of s or follows whitespace, and isn't within
+// a quoted scalar.
+func stripYAMLComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				if quote == '\'' && i+1 < len(s) && s[i+1] == '\'' {
+					i++
+					continue
+				}
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			// a quote only starts a quoted scalar if it is the
+			// start of a token, e.g. not the apostrophe in don't
+			if i == 0 || strings.IndexByte(" \t:-[{,", s[i-1]) >= 0 {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t'):
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// yamlParser parses the lines of a YAML document.
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+	// anchors are the values of the anchors, by name.
+	anchors map[string]interface{}
+}
+
+// peek returns the next line that isn't blank, without consuming it; nil is
+// returned if there isn't one.
+func (p *yamlParser) peek() *yamlLine {
+	for p.pos < len(p.lines) && p.lines[p.pos].text == "" {
+		p.pos++
+	}
+	if p.pos == len(p.lines) {
+		return nil
+	}
+	return &p.lines[p.pos]
+}
+
+// parseNode parses the node that starts at the next line, whose indent must be
+// at least indent.  If there isn't one, the node is null.
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	l := p.peek()
+	if l == nil || l.indent < indent {
+		return nil, nil
+	}
+	if strings.HasPrefix(l.text, "\t") {
+		return nil, l.errorf("tabs can't be used for indentation")
+	}
+	switch {
+	case isYAMLSeqEntry(l.text):
+		return p.parseSeq(l.indent)
+	case isYAMLMapEntry(l.text):
+		return p.parseMap(l.indent)
+	}
+	p.pos++
+	return p.parseValue(l.text, l.indent-1, l, false)
+}
+
+// parseMap parses the block mapping whose keys are at indent.
+func (p *yamlParser) parseMap(indent int) (interface{}, error) {
+	m := &orderedObject{}
+	// merged are the keys that were merged into m; they are overridden by
+	// the mapping's own keys
+	merged := map[string]bool{}
+	for {
+		l := p.peek()
+		if l == nil || l.indent < indent {
+			return m, nil
+		}
+		if l.indent > indent {
+			return nil, l.errorf("unexpected indentation")
+		}
+		key, rest, ok := splitYAMLKey(l.text)
+		if !ok {
+			if isYAMLSeqEntry(l.text) {
+				// a sequence that is the value of the previous key
+				// can be at the same indentation as the key
+				return m, nil
+			}
+			if isYAMLComplexKey(l.text) {
+				return nil, l.errorf("complex mapping keys aren't supported")
+			}
+			return nil, l.errorf("expected a mapping key")
+		}
+		p.pos++
+		v, err := p.parseValue(rest, indent, l, false)
+		if err != nil {
+			return nil, err
+		}
+		if key == "<<" {
+			err = mergeYAML(m, v, merged)
+			if err != nil {
+				return nil, l.errorf("%s", err)
+			}
+			continue
+		}
+		if _, ok := m.vals[key]; ok && !merged[key] {
+			return nil, l.errorf("duplicate key %q", key)
+		}
+		delete(merged, key)
+		m.set(key, v)
+	}
+}
+
+// mergeYAML adds the keys of the mapping, or sequence of mappings, v, that m
+// doesn't have to m.  The keys that are added are recorded in merged.
+func mergeYAML(m *orderedObject, v interface{}, merged map[string]bool) error {
+	var srcs []interface{}
+	switch v := v.(type) {
+	case *orderedObject:
+		srcs = []interface{}{v}
+	case []interface{}:
+		srcs = v
+	}
+	if len(srcs) == 0 {
+		return fmt.Errorf("a merge key's value must be a mapping or a sequence of mappings")
+	}
+	for _, src := range srcs {
+		o, ok := src.(*orderedObject)
+		if !ok {
+			return fmt.Errorf("a merge key's value must be a mapping or a sequence of mappings")
+		}
+		for _, k := range o.keys {
+			if _, ok := m.vals[k]; !ok {
+				m.set(k, o.vals[k])
+				merged[k] = true
+			}
+		}
+	}
+	return nil
+}
+
+// parseSeq parses the block sequence whose entries are at indent.
+func (p *yamlParser) parseSeq(indent int) (interface{}, error) {
+	a := []interface{}{}
+	for {
+		l := p.peek()
+		if l == nil || l.indent < indent || !isYAMLSeqEntry(l.text) {
+			if l != nil && l.indent > indent {
+				return nil, l.errorf("unexpected indentation")
+			}
+			return a, nil
+		}
+		if l.indent > indent {
+			return nil, l.errorf("unexpected indentation")
+		}
+		rest := l.text[1:]
+		entry := strings.TrimLeft(rest, " ")
+		var v interface{}
+		var err error
+		if isYAMLSeqEntry(entry) || isYAMLMapEntry(entry) {
+			// a compact nested node, e.g. - key: value, is parsed
+			// as if it started on its own line
+			l.indent += 1 + len(rest) - len(entry)
+			l.text = entry
+			v, err = p.parseNode(l.indent)
+		} else {
+			p.pos++
+			v, err = p.parseValue(entry, indent, l, true)
+		}
+		if err != nil {
+			return nil, err
+		}
+		a = append(a, v)
+	}
+}
+
+// parseValue parses the value, s, that follows a key or sequence entry, on
+// line l, whose indent is indent.  If s is empty, the value is the node on
+// the following lines.
+func (p *yamlParser) parseValue(s string, indent int, l *yamlLine, inSeq bool) (interface{}, error) {
+	var anchor, tag string
+	for {
+		switch {
+		case strings.HasPrefix(s, "&"):
+			anchor, s = splitYAMLToken(s[1:])
+			continue
+		case strings.HasPrefix(s, "!"):
+			tag, s = splitYAMLToken(s)
+			continue
+		}
+		break
+	}
+	var v interface{}
+	var err error
+	// text is the scalar's text, which an explicit tag is applied to
+	var text string
+	switch {
+	case strings.HasPrefix(s, "*"):
+		name := s[1:]
+		var ok bool
+		v, ok = p.anchors[name]
+		if !ok {
+			return nil, l.errorf("unknown alias %q", name)
+		}
+		return v, nil
+	case s == "":
+		next := p.peek()
+		switch {
+		case next == nil:
+		case next.indent > indent:
+			v, err = p.parseNode(indent + 1)
+		case next.indent == indent && !inSeq && isYAMLSeqEntry(next.text):
+			v, err = p.parseSeq(indent)
+		}
+	case s[0] == '|' || s[0] == '>':
+		v, err = p.parseBlockScalar(s, indent, l)
+	case s[0] == '[' || s[0] == '{':
+		v, err = p.parseFlow(s, l)
+	case s[0] == '"' || s[0] == '\'':
+		var n int
+		v, n, err = parseYAMLQuoted(s)
+		if err == nil && strings.TrimSpace(s[n:]) != "" {
+			err = fmt.Errorf("unexpected content after the quoted scalar")
+		}
+		if err != nil {
+			err = l.errorf("%s", err)
+		}
+	case isYAMLComplexKey(s):
+		return nil, l.errorf("complex mapping keys aren't supported")
+	default:
+		// a plain scalar may continue on the following, more
+		// indented, lines; they are folded into one line
+		for next := p.peek(); next != nil && next.indent > indent; next = p.peek() {
+			if isYAMLMapEntry(next.text) {
+				return nil, next.errorf("unexpected mapping key after a scalar")
+			}
+			s += " " + next.text
+			p.pos++
+		}
+		v, text = resolveYAMLPlain(s), s
+	}
+	if err != nil {
+		return nil, err
+	}
+	if tag != "" {
+		if str, ok := v.(string); ok {
+			text = str
+		}
+		v, err = applyYAMLTag(tag, v, text)
+		if err != nil {
+			return nil, l.errorf("%s", err)
+		}
+	}
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+	return v, nil
+}
+
+// applyYAMLTag applies the explicit tag, tag, to the node, v, whose text, if
+// it is a scalar, is text.  Only the core schema's tags are supported: the
+// scalar must be valid for the tag, e.g. !!int "3" is 3 but !!int "x" is an
+// error, and any other tag is an error.
+func applyYAMLTag(tag string, v interface{}, text string) (interface{}, error) {
+	// a verbatim tag, e.g. !<tag:yaml.org,2002:int>, is the same as its
+	// shorthand
+	if strings.HasPrefix(tag, "!<tag:yaml.org,2002:") && strings.HasSuffix(tag, ">") {
+		tag = "!!" + tag[len("!<tag:yaml.org,2002:"):len(tag)-1]
+	}
+	switch v.(type) {
+	case *orderedObject:
+		if tag == "!!map" {
+			return v, nil
+		}
+		return nil, fmt.Errorf("the %s tag can't be applied to a mapping", tag)
+	case []interface{}:
+		if tag == "!!seq" {
+			return v, nil
+		}
+		return nil, fmt.Errorf("the %s tag can't be applied to a sequence", tag)
+	}
+	switch tag {
+	case "!", "!!str":
+		return text, nil
+	case "!!null":
+		if resolveYAMLPlain(text) == nil {
+			return nil, nil
+		}
+	case "!!bool":
+		if b, ok := resolveYAMLPlain(text).(bool); ok {
+			return b, nil
+		}
+	case "!!int":
+		if n, ok := yamlNumber(text); ok && !strings.ContainsAny(string(n), ".eE") {
+			return n, nil
+		}
+	case "!!float":
+		if n, ok := yamlNumber(text); ok {
+			if !strings.ContainsAny(string(n), ".eE") {
+				n += ".0"
+			}
+			return n, nil
+		}
+	case "!!map", "!!seq":
+	default:
+		return nil, fmt.Errorf("the %s tag isn't supported, only the core schema's tags, e.g. !!str and !!int, are", tag)
+	}
+	return nil, fmt.Errorf("%q isn't a valid %s", text, tag)
+}
+
+// splitYAMLToken returns the token at the start of s, e.g. an anchor's name,
+// and what follows it.
+func splitYAMLToken(s string) (string, string) {
+	i := strings.IndexAny(s, " \t")
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], strings.TrimLeft(s[i:], " \t")
+}
+
+// parseBlockScalar parses the literal, |, or folded, >, block scalar whose
+// header is s and whose content is on the following lines.
+func (p *yamlParser) parseBlockScalar(s string, indent int, l *yamlLine) (interface{}, error) {
+	folded := s[0] == '>'
+	chomp := byte(0)
+	explicit := 0
+	for _, c := range s[1:] {
+		switch {
+		case c == '-' || c == '+':
+			chomp = byte(c)
+		case c >= '1' && c <= '9':
+			explicit = int(c - '0')
+		default:
+			return nil, l.errorf("invalid block scalar header %q", s)
+		}
+	}
+	content := -1
+	if explicit > 0 {
+		content = indent + explicit
+		if indent < 0 {
+			content = explicit
+		}
+	}
+	var lines []string
+	for ; p.pos < len(p.lines); p.pos++ {
+		raw := p.lines[p.pos].raw
+		trimmed := strings.TrimLeft(raw, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			continue
+		}
+		n := len(raw) - len(trimmed)
+		if content < 0 {
+			if n <= indent {
+				break
+			}
+			content = n
+		}
+		if n < content {
+			break
+		}
+		lines = append(lines, raw[content:])
+	}
+	// trailing blank lines are only kept with keep chomping
+	end := len(lines)
+	for end > 0 && lines[end-1] == "" {
+		end--
+	}
+	trailing := lines[end:]
+	lines = lines[:end]
+	var v string
+	if folded {
+		var buff strings.Builder
+		for i, line := range lines {
+			switch {
+			case i == 0:
+			case line == "" || lines[i-1] == "":
+				if line == "" {
+					buff.WriteByte('\n')
+				}
+			case strings.HasPrefix(line, " ") || strings.HasPrefix(lines[i-1], " "):
+				buff.WriteByte('\n')
+			default:
+				buff.WriteByte(' ')
+			}
+			buff.WriteString(line)
+		}
+		v = buff.String()
+	} else {
+		v = strings.Join(lines, "\n")
+	}
+	switch {
+	case len(lines) == 0:
+	case chomp == '-':
+	case chomp == '+':
+		v += "\n" + strings.Repeat("\n", len(trailing))
+	default:
+		v += "\n"
+	}
+	return v, nil
+}
+
+// parseFlow parses the flow collection that starts with s, and may continue
+// on the following lines.
+func (p *yamlParser) parseFlow(s string, l *yamlLine) (interface{}, error) {
+	for !flowBalanced(s) {
+		if p.pos == len(p.lines) {
+			return nil, l.errorf("unterminated flow collection")
+		}
+		s += " " + p.lines[p.pos].text
+		p.pos++
+	}
+	f := yamlFlow{s: s, p: p}
+	v, err := f.value()
+	if err != nil {
+		return nil, l.errorf("%s", err)
+	}
+	f.skipSpace()
+	if f.i < len(f.s) {
+		return nil, l.errorf("unexpected content after the flow collection")
+	}
+	return v, nil
+}
+
+// flowBalanced returns whether every bracket and brace in s, outside of
+// quoted scalars, is closed.
+func flowBalanced(s string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// yamlFlow parses a flow collection.
+type yamlFlow struct {
+	s string
+	i int
+	p *yamlParser
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.i < len(f.s) && (f.s[f.i] == ' ' || f.s[f.i] == '\t') {
+		f.i++
+	}
+}
+
+// value parses the flow node at the current position.
+func (f *yamlFlow) value() (interface{}, error) {
+	f.skipSpace()
+	if f.i == len(f.s) {
+		return nil, fmt.Errorf("unexpected end of flow collection")
+	}
+	switch c := f.s[f.i]; c {
+	case '[':
+		f.i++
+		a := []interface{}{}
+		for {
+			f.skipSpace()
+			if f.i < len(f.s) && f.s[f.i] == ']' {
+				f.i++
+				return a, nil
+			}
+			v, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			a = append(a, v)
+			if err := f.sep(']'); err != nil {
+				return nil, err
+			}
+		}
+	case '{':
+		f.i++
+		m := &orderedObject{}
+		for {
+			f.skipSpace()
+			if f.i < len(f.s) && f.s[f.i] == '}' {
+				f.i++
+				return m, nil
+			}
+			k, err := f.value()
+			if err != nil {
+				return nil, err
+			}
+			var v interface{}
+			f.skipSpace()
+			if f.i < len(f.s) && f.s[f.i] == ':' {
+				f.i++
+				v, err = f.value()
+				if err != nil {
+					return nil, err
+				}
+			}
+			key, ok := k.(string)
+			if !ok {
+				key = fmt.Sprint(k)
+			}
+			m.set(key, v)
+			if err := f.sep('}'); err != nil {
+				return nil, err
+			}
+		}
+	case '"', '\'':
+		v, n, err := parseYAMLQuoted(f.s[f.i:])
+		if err != nil {
+			return nil, err
+		}
+		f.i += n
+		return v, nil
+	case '*':
+		start := f.i + 1
+		for f.i++; f.i < len(f.s) && strings.IndexByte(" ,]}", f.s[f.i]) < 0; f.i++ {
+		}
+		v, ok := f.p.anchors[f.s[start:f.i]]
+		if !ok {
+			return nil, fmt.Errorf("unknown alias %q", f.s[start:f.i])
+		}
+		return v, nil
+	case '!':
+		start := f.i
+		for ; f.i < len(f.s) && strings.IndexByte(" ,]}", f.s[f.i]) < 0; f.i++ {
+		}
+		tag := f.s[start:f.i]
+		f.skipSpace()
+		start = f.i
+		v, err := f.value()
+		if err != nil {
+			return nil, err
+		}
+		text, ok := v.(string)
+		if !ok {
+			text = strings.TrimSpace(f.s[start:f.i])
+		}
+		return applyYAMLTag(tag, v, text)
+	}
+	// a plain scalar ends at a flow indicator or a : that is followed by
+	// a space or flow indicator
+	start := f.i
+	for ; f.i < len(f.s); f.i++ {
+		c := f.s[f.i]
+		if c == ',' || c == ']' || c == '}' {
+			break
+		}
+		if c == ':' && (f.i+1 == len(f.s) || strings.IndexByte(" ,]}", f.s[f.i+1]) >= 0) {
+			break
+		}
+	}
+	return resolveYAMLPlain(strings.TrimSpace(f.s[start:f.i])), nil
+}
+
+// sep consumes the separator that follows an entry, see entrySep.
+func (f *yamlFlow) sep(end byte) error {
+	f.skipSpace()
+	if f.i == len(f.s) {
+		return fmt.Errorf("unterminated flow collection")
+	}
+	n, err := entrySep(f.s[f.i:], end)
+	if err != nil {
+		return err
+	}
+	f.i += n
+	return nil
+}
+
+// isYAMLSeqEntry returns whether s is a block sequence entry.
+func isYAMLSeqEntry(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ")
+}
+
+// isYAMLComplexKey returns whether s is a complex mapping key, e.g. ? a.
+func isYAMLComplexKey(s string) bool {
+	return s == "?" || strings.HasPrefix(s, "? ")
+}
+
+// isYAMLMapEntry returns whether s is a block mapping entry.
+func isYAMLMapEntry(s string) bool {
+	_, _, ok := splitYAMLKey(s)
+	return ok
+}
+
+// splitYAMLKey splits the block mapping entry, s, into its key and the rest
+// of the line.  If s isn't a mapping entry, ok is false.
+func splitYAMLKey(s string) (key, rest string, ok bool) {
+	if s == "" || strings.IndexByte("[{-?|>*&!%@`", s[0]) >= 0 && !(s[0] == '-' && len(s) > 1 && s[1] != ' ') {
+		return "", "", false
+	}
+	if s[0] == '"' || s[0] == '\'' {
+		v, n, err := parseYAMLQuoted(s)
+		if err != nil {
+			return "", "", false
+		}
+		after := strings.TrimLeft(s[n:], " ")
+		if after != ":" && !strings.HasPrefix(after, ": ") {
+			return "", "", false
+		}
+		return v.(string), strings.TrimSpace(after[1:]), true
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t') {
+			return strings.TrimRight(s[:i], " \t"), strings.TrimSpace(s[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// parseYAMLQuoted parses the single, or double, quoted scalar at the start of
+// s.  The number of bytes it is is also returned.
+func parseYAMLQuoted(s string) (interface{}, int, error) {
+	q := s[0]
+	var buff strings.Builder
+	for i := 1; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == q && q == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			buff.WriteByte('\'')
+			i++
+		case c == q:
+			return buff.String(), i + 1, nil
+		case c == '\\' && q == '"':
+			if i+1 == len(s) {
+				return nil, 0, fmt.Errorf("unterminated escape")
+			}
+			i++
+			n, err := yamlEscape(&buff, s[i:])
+			if err != nil {
+				return nil, 0, err
+			}
+			i += n
+		default:
+			buff.WriteByte(c)
+		}
+	}
+	return nil, 0, fmt.Errorf("unterminated quoted scalar: quoted scalars that span lines aren't supported")
+}
+
+// yamlEscapes are the single character escapes of double-quoted scalars.
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n",
+	'v': "\v", 'f': "\f", 'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"",
+	'/': "/", '\\': "\\", 'N': "\u0085", '_': " ", 'L': " ",
+	'P': " ",
+}
+
+// yamlEscape writes the escape sequence, without its backslash, at the start
+// of s to buff.  The number of bytes, after the first, that were consumed is
+// returned.
+func yamlEscape(buff *strings.Builder, s string) (int, error) {
+	if e, ok := yamlEscapes[s[0]]; ok {
+		buff.WriteString(e)
+		return 0, nil
+	}
+	var n int
+	switch s[0] {
+	case 'x':
+		n = 2
+	case 'u':
+		n = 4
+	case 'U':
+		n = 8
+	default:
+		return 0, fmt.Errorf("invalid escape \\%c", s[0])
+	}
+	if len(s) < n+1 {
+		return 0, fmt.Errorf("invalid escape \\%s", s)
+	}
+	r, err := strconv.ParseUint(s[1:n+1], 16, 32)
+	if err != nil || !utf8.ValidRune(rune(r)) {
+		return 0, fmt.Errorf("invalid escape \\%s", s[:n+1])
+	}
+	buff.WriteRune(rune(r))
+	return n, nil
+}
+
+// resolveYAMLPlain returns the value of the plain scalar, s, using the YAML
+// 1.2 core schema: null, bools, ints, and floats.  Anything else is a
+// string.  Floats that JSON can't represent, e.g. .inf, are strings.
+func resolveYAMLPlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	}
+	if n, ok := yamlNumber(s); ok {
+		return n
+	}
+	return s
+}
+
+// yamlNumber returns s as a json.Number if it is a YAML int or float that can
+// be represented in JSON.
+func yamlNumber(s string) (json.Number, bool) {
+	if s == "" || strings.IndexByte("+-.0123456789", s[0]) < 0 {
+		return "", false
+	}
+	for _, base := range []struct {
+		prefix string
+		base   int
+	}{{"0x", 16}, {"0o", 8}} {
+		if strings.HasPrefix(s, base.prefix) {
+			v, err := strconv.ParseInt(s[2:], base.base, 64)
+			if err != nil {
+				return "", false
+			}
+			return json.Number(strconv.FormatInt(v, 10)), true
+		}
+	}
+	return jsonNumber(s)
+}
+
+// jsonNumber returns s as a json.Number if it is a decimal number: the
+// literal is kept if it is a valid JSON number, otherwise it is normalized,
+// e.g. +1 is 1, .5 is 0.5, and 5. is 5.0, so that it is still a float.
+func jsonNumber(s string) (json.Number, bool) {
+	if strings.ContainsAny(s, "xXoObBnNiI_") {
+		return "", false
+	}
+	if json.Valid([]byte(s)) && s[0] != '"' {
+		return json.Number(s), true
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", false
+	}
+	if v == float64(int64(v)) && !strings.ContainsAny(s, ".eE") {
+		return json.Number(strconv.FormatInt(int64(v), 10)), true
+	}
+	n := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(n, ".eE") {
+		n += ".0"
+	}
+	return json.Number(n), true
+}
@@ -0,0 +1,68 @@
+package json2go
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		yaml     string
+		expected string
+		err      string
+	}{
+		{"a: 1\nb: x\n", `{"a":1,"b":"x"}`, ""},
+		{"# comment\nb: 1 # trailing\na: 'x # y'\n", `{"b":1,"a":"x # y"}`, ""},
+		{"a:\n  b:\n    c: true\n", `{"a":{"b":{"c":true}}}`, ""},
+		{"a:\n- 1\n- 2\nb: x\n", `{"a":[1,2],"b":"x"}`, ""},
+		{"- name: a\n  ids: [1, 2]\n- name: b\n", `[{"name":"a","ids":[1,2]},{"name":"b"}]`, ""},
+		{"a: {b: 1, c: [x, \"y\"]}\n", `{"a":{"b":1,"c":["x","y"]}}`, ""},
+		{"a: [1,\n  2]\n", `{"a":[1,2]}`, ""},
+		{"a: ~\nb: null\nc:\nd: yes\ne: False\n", `{"a":null,"b":null,"c":null,"d":"yes","e":false}`, ""},
+		{"a: 0x1f\nb: 0o17\nc: +1\nd: .5\ne: 1.0\nf: .inf\n", `{"a":31,"b":15,"c":1,"d":0.5,"e":1.0,"f":".inf"}`, ""},
+		{"a: \"tab\\there\\u00e9\"\nb: 'it''s'\nc: don't\n", `{"a":"tab\thereé","b":"it's","c":"don't"}`, ""},
+		{"a: |\n  x\n  y\n\nb: >-\n  x\n  y\n\n  z\n", `{"a":"x\ny\n","b":"x y\nz"}`, ""},
+		{"a: this is\n  plain\n", `{"a":"this is plain"}`, ""},
+		{"base: &base\n  a: 1\n  b: 2\nc:\n  <<: *base\n  b: 3\nd: *base\n", `{"base":{"a":1,"b":2},"c":{"a":1,"b":3},"d":{"a":1,"b":2}}`, ""},
+		{"a: !!str 1\n", `{"a":"1"}`, ""},
+		{"a: !!int \"3\"\nb: !!float 3\nc: !!bool 'true'\nd: !!null\ne: ! 1\n", `{"a":3,"b":3.0,"c":true,"d":null,"e":"1"}`, ""},
+		{"a: !!map {b: !!int '1'}\nc: !<tag:yaml.org,2002:str> 2\n", `{"a":{"b":1},"c":"2"}`, ""},
+		{"%YAML 1.2\n---\na: 1\n...\n", `{"a":1}`, ""},
+		{"a: 1\n---\na: 2\n", `[{"a":1},{"a":2}]`, ""},
+		{"", "", "no documents"},
+		{"a: 1\na: 2\n", "", "line 2: duplicate key"},
+		{"a: *b\n", "", "line 1: unknown alias"},
+		{"a: 1\n  b: 2\n", "", "line 2: unexpected mapping key"},
+		{"a:\n  b: 1\n c: 2\n", "", "line 3: unexpected indentation"},
+		{"a: \"x\n  y\"\n", "", "quoted scalars that span lines aren't supported"},
+		{"? a\n: 1\n", "", "line 1: complex mapping keys aren't supported"},
+		{"a: 1\n? b\n: 2\n", "", "line 2: complex mapping keys aren't supported"},
+		{"a: !foo x\n", "", "line 1: the !foo tag isn't supported"},
+		{"a: [!foo x]\n", "", "the !foo tag isn't supported"},
+		{"a: !!int 1.5\n", "", `"1.5" isn't a valid !!int`},
+		{"a: !!str [1]\n", "", "the !!str tag can't be applied to a sequence"},
+		{"a: [1, 2\n", "", "unterminated flow collection"},
+	}
+	for i, test := range tests {
+		v, err := decodeYAML([]byte(test.yaml))
+		if err != nil {
+			if test.err == "" || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%d: got error %q, want %q", i, err, test.err)
+			}
+			continue
+		}
+		if test.err != "" {
+			t.Errorf("%d: expected error %q, got none", i, test.err)
+			continue
+		}
+		b, err := json.Marshal(v)
+		if err != nil {
+			t.Errorf("%d: unexpected error: %s", i, err)
+			continue
+		}
+		if string(b) != test.expected {
+			t.Errorf("%d: got %s want %s", i, b, test.expected)
+		}
+	}
+}